@@ -0,0 +1,14 @@
+package catalog
+
+import (
+	"github.com/urfave/cli"
+)
+
+// Catalog cli command to work with OSCAL catalogs
+var Catalog = cli.Command{
+	Name:  "catalog",
+	Usage: "work with OSCAL catalogs",
+	Subcommands: []cli.Command{
+		Diff,
+	},
+}
@@ -0,0 +1,77 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/oscalkit/generator"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/urfave/cli"
+)
+
+var diffOutput string
+var diffJSON bool
+
+// Diff compares two revisions of a catalog
+var Diff = cli.Command{
+	Name:      "diff",
+	Usage:     "compare two revisions of a catalog",
+	ArgsUsage: "[old catalog] [new catalog]",
+	Description: `Compare two revisions of a catalog by control id and report the controls and
+	 subcontrols added, removed and withdrawn, along with the changed titles, prose of parts,
+	 word by word, and parameters. Catalogs are read from paths or URLs.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "output file for the diff. Defaults to STDOUT",
+			Destination: &diffOutput,
+		},
+		cli.BoolFlag{
+			Name:        "json, j",
+			Usage:       "write the diff as JSON instead of text",
+			Destination: &diffJSON,
+		},
+	},
+	Before: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			return cli.NewExitError("oscalkit catalog diff requires two catalog arguments", 1)
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) error {
+		from, err := generator.FetchCatalog(context.Background(), c.Args().Get(0))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot read old catalog, err: %v", err), 1)
+		}
+		to, err := generator.FetchCatalog(context.Background(), c.Args().Get(1))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot read new catalog, err: %v", err), 1)
+		}
+		d := catalog.Diff(from, to)
+
+		var w io.Writer = os.Stdout
+		if diffOutput != "" {
+			out, err := os.Create(filepath.Clean(diffOutput))
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+			defer out.Close()
+			w = out
+		}
+		if diffJSON {
+			e := json.NewEncoder(w)
+			e.SetIndent("", "  ")
+			err = e.Encode(d)
+		} else {
+			err = d.WriteText(w)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write diff, err: %v", err), 1)
+		}
+		return nil
+	},
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/docker/oscalkit/cli/cmd/catalog"
 	"github.com/docker/oscalkit/cli/cmd/convert"
 	"github.com/docker/oscalkit/cli/cmd/generate"
+	"github.com/docker/oscalkit/cli/cmd/profile"
 	"github.com/docker/oscalkit/cli/version"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -41,6 +43,10 @@ func Execute() error {
 		Validate,
 		Sign,
 		generate.Generate,
+		Resolve,
+		CompareProfiles,
+		profile.Profile,
+		catalog.Catalog,
 	}
 
 	return app.Run(os.Args)
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/oscalkit/generator"
+	"github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/urfave/cli"
+)
+
+var compareOutput string
+var compareFormat string
+var compareCacheDir string
+var compareOffline bool
+
+// CompareProfiles compares the resolved baselines of two profiles
+var CompareProfiles = cli.Command{
+	Name:  "compare-profiles",
+	Usage: "compare the controls, parameters and alters of two resolved profiles",
+	Description: `Resolve two profiles, A and B, and report the controls and subcontrols only in A,
+	 only in B and in both, along with the parameter values and alters which differ for the
+	 controls in both.`,
+	ArgsUsage: "[profile A] [profile B]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:        "format, f",
+			Usage:       "format of the report: text, json or csv",
+			Value:       "text",
+			Destination: &compareFormat,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "output file for the report. Defaults to STDOUT",
+			Destination: &compareOutput,
+		},
+		cli.StringFlag{
+			Name:        "cache-dir",
+			Usage:       "directory caching documents imported over http(s) and resolved catalogs",
+			Value:       generator.DefaultCacheDir(),
+			Destination: &compareCacheDir,
+		},
+		cli.BoolFlag{
+			Name:        "offline",
+			Usage:       "resolve http(s) imports from the cache only",
+			Destination: &compareOffline,
+		},
+	},
+	Before: func(c *cli.Context) error {
+		if c.NArg() != 2 {
+			return cli.NewExitError("oscalkit compare-profiles requires two profile arguments", 1)
+		}
+		switch compareFormat {
+		case "text", "json", "csv":
+			return nil
+		}
+		return cli.NewExitError(fmt.Sprintf("unknown format %s, expected text, json or csv", compareFormat), 1)
+	},
+	Action: func(c *cli.Context) error {
+		fetcher := generator.NewFetcher(compareCacheDir, compareOffline)
+		cache := generator.NewCache(generator.ResolutionCacheDir(compareCacheDir))
+		a, aOpts, err := readComparedProfile(c.Args().Get(0), fetcher, cache)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		b, bOpts, err := readComparedProfile(c.Args().Get(1), fetcher, cache)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		cmp, err := generator.Compare(context.Background(), a, aOpts, b, bOpts)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		var w io.Writer = os.Stdout
+		if compareOutput != "" {
+			out, err := os.Create(filepath.Clean(compareOutput))
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+			defer out.Close()
+			w = out
+		}
+		switch compareFormat {
+		case "json":
+			e := json.NewEncoder(w)
+			e.SetIndent("", "  ")
+			err = e.Encode(cmp)
+		case "csv":
+			err = cmp.WriteCSV(w)
+		default:
+			err = cmp.WriteText(w)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write comparison, err: %v", err), 1)
+		}
+		return nil
+	},
+}
+
+// readComparedProfile reads a profile to compare along with the options resolving it
+func readComparedProfile(path string, fetcher generator.Fetcher, cache *generator.Cache) (*profile.Profile, generator.Options, error) {
+	profilePath, err := generator.GetAbsolutePath(path)
+	if err != nil {
+		return nil, generator.Options{}, fmt.Errorf("cannot get absolute path, err: %v", err)
+	}
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil, generator.Options{}, err
+	}
+	defer f.Close()
+	p, err := generator.ReadProfile(f)
+	if err != nil {
+		return nil, generator.Options{}, err
+	}
+	p, err = generator.SetBasePath(p, profilePath)
+	if err != nil {
+		return nil, generator.Options{}, fmt.Errorf("failed to setup href path for profiles: %v", err)
+	}
+	return p, generator.Options{Href: profilePath, Fetcher: fetcher, Cache: cache}, nil
+}
@@ -5,6 +5,7 @@ import (
 )
 
 var yaml bool
+var jsonEncoding string
 
 // Convert ...
 var Convert = cli.Command{
@@ -1,8 +1,7 @@
 package convert
 
 import (
-	"encoding/json"
-	"encoding/xml"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -24,9 +23,9 @@ var outputFile string
 var ConvertOSCAL = cli.Command{
 	Name:  "oscal",
 	Usage: "convert between one or more OSCAL file formats",
-	Description: `Convert between OSCAL-formatted XML and JSON files. The command accepts
-   one or more source file paths and can also be used with source file contents
-	 piped/redirected from STDIN.`,
+	Description: `Convert between OSCAL-formatted XML and JSON files. YAML sources, such as
+   written with --yaml, are converted to XML. The command accepts one or more source
+	 file paths and can also be used with source file contents piped/redirected from STDIN.`,
 	ArgsUsage: "[source-files...]",
 	Flags: []cli.Flag{
 		cli.StringFlag{
@@ -44,6 +43,12 @@ var ConvertOSCAL = cli.Command{
 			Usage:       "If source file format is XML or JSON, also generate equivalent YAML output",
 			Destination: &yaml,
 		},
+		cli.StringFlag{
+			Name:        "json-encoding",
+			Usage:       "encoding of JSON output: legacy, with camelCase keys, or official, as the OSCAL JSON schemas",
+			Value:       string(oscal.LegacyJSON),
+			Destination: &jsonEncoding,
+		},
 	},
 	Before: func(c *cli.Context) error {
 		if c.NArg() < 1 {
@@ -69,12 +74,18 @@ var ConvertOSCAL = cli.Command{
 			return cli.NewExitError("--output-file (-f) is only used when converting from STDIN (-)", 1)
 		}
 
+		switch oscal.JSONEncoding(jsonEncoding) {
+		case oscal.LegacyJSON, oscal.OfficialJSON:
+		default:
+			return cli.NewExitError(fmt.Sprintf("unknown JSON encoding %s, expected legacy or official", jsonEncoding), 1)
+		}
+
 		return nil
 	},
 	Action: func(c *cli.Context) error {
 		// Parse stdin via pipe or redirection
 		if c.NArg() <= 0 || c.Args().First() == "-" {
-			outputFormat, err := validateStdin(os.Stdin)
+			source, outputFormat, err := validateStdin(os.Stdin)
 			if err != nil {
 				return cli.NewExitError(fmt.Sprintf("Error parsing from STDIN: %s", err), 1)
 			}
@@ -85,7 +96,7 @@ var ConvertOSCAL = cli.Command{
 			}
 			defer destFile.Close()
 
-			return convert(os.Stdin, destFile, outputFormat)
+			return convert(source, destFile, outputFormat)
 		}
 
 		// Convert each source file
@@ -104,23 +115,9 @@ var ConvertOSCAL = cli.Command{
 			matches, _ := filepath.Glob(sourcePath)
 
 			for _, match := range matches {
-				srcFile, err := os.Open(match)
-				if err != nil {
+				if err := convertFile(match); err != nil {
 					return err
 				}
-				defer srcFile.Close()
-
-				destPath, outputFormat := createOutputPath(sourcePath)
-
-				destFile, err := os.Create(destPath)
-				if err != nil {
-					return err
-				}
-				defer destFile.Close()
-
-				if err := convert(srcFile, destFile, outputFormat); err != nil {
-					return cli.NewExitError(fmt.Sprintf("Error converting to OSCAL from file %s: %s", match, err), 1)
-				}
 			}
 		}
 
@@ -128,31 +125,69 @@ var ConvertOSCAL = cli.Command{
 	},
 }
 
-func validateStdin(stdin *os.File) (string, error) {
-	rawSource, err := ioutil.ReadAll(os.Stdin)
+// convertFile converts a source file, and to YAML too when asked. Its files are closed before
+// the next source file is converted.
+func convertFile(match string) error {
+	srcFile, err := os.Open(match)
 	if err != nil {
-		return "", err
+		return err
 	}
+	defer srcFile.Close()
 
-	var xmls string
-	var jsons string
+	destPath, outputFormat := createOutputPath(match)
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	err = convert(srcFile, destFile, outputFormat)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error converting to OSCAL from file %s: %s", match, err), 1)
+	}
 
-	if err = xml.Unmarshal(rawSource, &xmls); err == nil {
-		if outputFile == "" {
-			outputFile = "stdin.json"
-		}
+	if !yaml || isYAML(match) {
+		return nil
+	}
+	if _, err := srcFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	yamlFile, err := os.Create(outputFilePath(match, "yaml"))
+	if err != nil {
+		return err
+	}
+	err = convert(srcFile, yamlFile, "yaml")
+	if closeErr := yamlFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Error converting to YAML from file %s: %s", match, err), 1)
+	}
+	return nil
+}
 
-		return "json", nil
-	} else if err = json.Unmarshal(rawSource, &jsons); err == nil {
-		if outputFile == "" {
-			outputFile = "stdin.xml"
-		}
+// validateStdin reads OSCAL from STDIN, giving it back along with the format to convert it to:
+// JSON for XML, XML for JSON and YAML
+func validateStdin(stdin *os.File) (io.Reader, string, error) {
+	rawSource, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return nil, "", err
+	}
 
-		return "xml", nil
+	if _, err := oscal.New(bytes.NewReader(rawSource)); err != nil {
+		return nil, "", errors.New("File content from STDIN is neither XML, JSON nor YAML")
 	}
 
-	return "", errors.New("File content from STDIN is neither XML nor JSON")
+	outputFormat := "xml"
+	if trimmed := bytes.TrimSpace(rawSource); len(trimmed) > 0 && trimmed[0] == '<' {
+		outputFormat = "json"
+	}
+	if outputFile == "" {
+		outputFile = "stdin." + outputFormat
+	}
 
+	return bytes.NewReader(rawSource), outputFormat, nil
 }
 
 // Not yet parsing rawSource arg for STDIN
@@ -166,7 +201,7 @@ func convert(src io.Reader, dest io.Writer, outputFormat string) error {
 			return err
 		}
 
-		if err := o.JSON(dest, true); err != nil {
+		if err := o.EncodeJSON(dest, true, oscal.JSONEncoding(jsonEncoding)); err != nil {
 			return err
 		}
 
@@ -233,11 +268,21 @@ func createOutputPath(srcPath string) (string, string) {
 		outputFormat = "xml"
 	}
 
+	return outputFilePath(srcPath, outputFormat), outputFormat
+}
+
+// outputFilePath names the file a source is converted to in the given format
+func outputFilePath(srcPath, outputFormat string) string {
 	filePath := fmt.Sprintf("%s.%s", strings.Split(path.Base(srcPath), ".")[0], outputFormat)
 
 	if outputPath != "" {
 		filePath = path.Join(outputPath, filePath)
 	}
 
-	return filePath, outputFormat
+	return filePath
+}
+
+func isYAML(srcPath string) bool {
+	ext := filepath.Ext(srcPath)
+	return ext == ".yaml" || ext == ".yml"
 }
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -13,7 +14,10 @@ import (
 	"github.com/urfave/cli"
 )
 
-var isJSON bool
+var (
+	isJSON      bool
+	keepInserts bool
+)
 
 // Catalog generates json/xml catalogs
 var Catalog = cli.Command{
@@ -37,6 +41,17 @@ var Catalog = cli.Command{
 			Usage:       "flag for generating catalogs in json",
 			Destination: &isJSON,
 		},
+		cli.StringFlag{
+			Name:        "cache-dir",
+			Usage:       "directory caching documents imported over http(s) and resolved catalogs",
+			Value:       generator.DefaultCacheDir(),
+			Destination: &cacheDir,
+		},
+		cli.BoolFlag{
+			Name:        "keep-inserts",
+			Usage:       "keep the insert markers of prose instead of replacing them with parameter values",
+			Destination: &keepInserts,
+		},
 	},
 	Before: func(c *cli.Context) error {
 		if profilePath == "" {
@@ -71,10 +86,13 @@ var Catalog = cli.Command{
 			return cli.NewExitError(fmt.Errorf("failed to setup href path for profiles: %v", err), 1)
 		}
 
-		catalogs, err := generator.CreateCatalogsFromProfile(profile)
+		catalogs, err := generator.ResolveImports(context.Background(), profile, resolveOptions(profilePath))
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("cannot create catalogs from profile, err: %v", err), 1)
 		}
+		if !keepInserts {
+			insertParamValues(catalogs)
+		}
 
 		var bytes []byte
 		if !isJSON {
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"context"
 	"fmt"
 	"go/format"
 	"io/ioutil"
@@ -17,6 +18,7 @@ import (
 var profilePath string
 var outputFileName string
 var packageName string
+var cacheDir string
 
 // Code Cli command to generate go code for controls in catalog
 var Code = cli.Command{
@@ -40,6 +42,12 @@ var Code = cli.Command{
 			Destination: &packageName,
 			Value:       "oscalkit",
 		},
+		cli.StringFlag{
+			Name:        "cache-dir",
+			Usage:       "directory caching documents imported over http(s) and resolved catalogs",
+			Value:       generator.DefaultCacheDir(),
+			Destination: &cacheDir,
+		},
 	},
 	Before: func(c *cli.Context) error {
 		if profilePath == "" {
@@ -81,10 +89,11 @@ var Code = cli.Command{
 		if err != nil {
 			return cli.NewExitError(fmt.Errorf("failed to setup href path for profiles: %v", err), 1)
 		}
-		catalogs, err := generator.CreateCatalogsFromProfile(profile)
+		catalogs, err := generator.ResolveImports(context.Background(), profile, resolveOptions(profilePath))
 		if err != nil {
 			return cli.NewExitError(fmt.Sprintf("cannot create catalogs from profile, err: %v", err), 1)
 		}
+		insertParamValues(catalogs)
 		t, err := templates.GetCatalogTemplate()
 		if err != nil {
 			return cli.NewExitError("cannot fetch template", 1)
@@ -1,6 +1,8 @@
 package generate
 
 import (
+	"github.com/docker/oscalkit/generator"
+	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/urfave/cli"
 )
 
@@ -14,3 +16,21 @@ var Generate = cli.Command{
 		Implementation,
 	},
 }
+
+// resolveOptions gives the options resolving the profile at profilePath, reusing the documents
+// and resolutions cached under cacheDir
+func resolveOptions(profilePath string) generator.Options {
+	return generator.Options{
+		Href:    profilePath,
+		Fetcher: generator.NewFetcher(cacheDir, false),
+		Cache:   generator.NewCache(generator.ResolutionCacheDir(cacheDir)),
+	}
+}
+
+// insertParamValues replaces the insert markers in the prose of resolved catalogs with the values
+// set for their parameters, as generated catalogs always had them
+func insertParamValues(catalogs []*catalog.Catalog) {
+	for _, c := range catalogs {
+		generator.InsertParamValues(c)
+	}
+}
@@ -2,6 +2,7 @@ package generate
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"go/format"
@@ -9,10 +10,12 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/docker/oscalkit/generator"
 	"github.com/docker/oscalkit/impl"
 	"github.com/docker/oscalkit/templates"
+	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/implementation"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -20,6 +23,9 @@ import (
 
 var profile string
 var excelSheet string
+var idScheme string
+var catalogID string
+var catalogPath string
 
 //Implementation generates implemntation
 var Implementation = cli.Command{
@@ -43,6 +49,22 @@ var Implementation = cli.Command{
 			Destination: &packageName,
 			Value:       "oscalkit",
 		},
+		cli.StringFlag{
+			Name:        "catalog, c",
+			Usage:       "catalog the controls are implemented against, giving the control-id scheme and the catalog id",
+			Destination: &catalogPath,
+		},
+		cli.StringFlag{
+			Name:        "id-scheme",
+			Usage:       fmt.Sprintf("control-id scheme overriding the detected one, one of %s", strings.Join(impl.Schemes(), ", ")),
+			Destination: &idScheme,
+		},
+		cli.StringFlag{
+			Name:        "catalog-id",
+			Usage:       "id of the catalog the controls are implemented against, overriding the id of --catalog",
+			Destination: &catalogID,
+			Value:       "NIST_SP-800-53",
+		},
 	},
 	Before: func(c *cli.Context) error {
 		if excelSheet == "" {
@@ -65,12 +87,6 @@ var Implementation = cli.Command{
 			return err
 		}
 
-		outputFile, err := os.Create(outputFileName)
-		if err != nil {
-			return fmt.Errorf("cannot create file for implementation: err: %v", err)
-		}
-		defer outputFile.Close()
-
 		var records [][]string
 		reader := bytes.NewReader(b)
 		r := csv.NewReader(reader)
@@ -85,12 +101,20 @@ var Implementation = cli.Command{
 			records = append(records, record)
 		}
 
-		catalog := impl.NISTCatalog{ID: "NIST_SP-800-53"}
-		implementationData := impl.GenerateImplementation(records, &catalog)
+		schemeCatalog, err := implementationCatalog(c, records)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		implementationData := impl.GenerateImplementation(records, schemeCatalog)
 		t, err := templates.GetImplementationTemplate()
 		if err != nil {
 			return fmt.Errorf("cannot get implementation template err %v", err)
 		}
+		outputFile, err := os.Create(outputFileName)
+		if err != nil {
+			return fmt.Errorf("cannot create file for implementation: err: %v", err)
+		}
+		defer outputFile.Close()
 		err = t.Execute(outputFile, struct {
 			Implementation implementation.Implementation
 			PackageName    string
@@ -114,3 +138,36 @@ var Implementation = cli.Command{
 		return nil
 	},
 }
+
+// implementationCatalog gives the catalog the excel sheet implements. Its scheme is detected from
+// the --catalog when given, from the control ids of the sheet otherwise, unless --id-scheme is set.
+func implementationCatalog(c *cli.Context, records [][]string) (*impl.SchemeCatalog, error) {
+	schemeCatalog := &impl.SchemeCatalog{ID: catalogID}
+	var ctlg *catalog.Catalog
+	if catalogPath != "" {
+		var err error
+		if ctlg, err = generator.FetchCatalog(context.Background(), catalogPath); err != nil {
+			return nil, fmt.Errorf("cannot read catalog, err: %v", err)
+		}
+		if !c.IsSet("catalog-id") && ctlg.Id != "" {
+			schemeCatalog.ID = ctlg.Id
+		}
+	}
+	switch {
+	case idScheme != "":
+		scheme, err := impl.LookupScheme(idScheme)
+		if err != nil {
+			return nil, err
+		}
+		schemeCatalog.Scheme = scheme
+	case ctlg != nil:
+		if schemeCatalog.Scheme = impl.DetectScheme(ctlg); schemeCatalog.Scheme == nil {
+			return nil, fmt.Errorf("cannot detect the control-id scheme of %s, set it with --id-scheme", catalogPath)
+		}
+	default:
+		if schemeCatalog.Scheme = impl.DetectIDScheme(impl.ControlIDs(records)); schemeCatalog.Scheme == nil {
+			return nil, fmt.Errorf("cannot detect the control-id scheme of %s, set it with --id-scheme or --catalog", excelSheet)
+		}
+	}
+	return schemeCatalog, nil
+}
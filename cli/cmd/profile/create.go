@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/oscalkit/generator"
+	"github.com/docker/oscalkit/impl"
+	"github.com/docker/oscalkit/types/oscal"
+	oscalprofile "github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/urfave/cli"
+)
+
+var catalogPath string
+var importHref string
+var profileID string
+var profileTitle string
+var withSubcontrols bool
+var outputFile string
+var isJSON bool
+
+// Create creates a profile selecting controls of a catalog
+var Create = cli.Command{
+	Name:  "create",
+	Usage: "create a profile selecting controls of a catalog by id or by family",
+	Description: `Create a profile importing a catalog and selecting the given controls and subcontrols,
+	 or all the controls of the given families. Ids may be written in any notation of the
+	 catalog's control-id scheme, such as AC-2(1) for ac-2.1. Every id and family is checked
+	 against the catalog.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:        "catalog, c",
+			Usage:       "path or URL of the catalog to import",
+			Destination: &catalogPath,
+		},
+		cli.StringSliceFlag{
+			Name:  "controls",
+			Usage: "comma separated ids of the controls and subcontrols to select, may be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "family, f",
+			Usage: "id or title of a family (group) of the catalog whose controls to select, may be repeated",
+		},
+		cli.BoolFlag{
+			Name:        "with-subcontrols",
+			Usage:       "select the subcontrols of the selected controls",
+			Destination: &withSubcontrols,
+		},
+		cli.StringFlag{
+			Name:        "href",
+			Usage:       "href of the catalog in the profile. Defaults to the catalog URL, or its path relative to the output file",
+			Destination: &importHref,
+		},
+		cli.StringFlag{
+			Name:        "id",
+			Usage:       "id of the profile",
+			Destination: &profileID,
+		},
+		cli.StringFlag{
+			Name:        "title",
+			Usage:       "title of the profile",
+			Destination: &profileTitle,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "output file for the profile. Defaults to STDOUT",
+			Destination: &outputFile,
+		},
+		cli.BoolFlag{
+			Name:        "json, j",
+			Usage:       "write the profile as JSON instead of XML",
+			Destination: &isJSON,
+		},
+	},
+	Before: func(c *cli.Context) error {
+		if catalogPath == "" {
+			return cli.NewExitError("oscalkit profile create is missing the --catalog flag", 1)
+		}
+		if len(c.StringSlice("controls")) == 0 && len(c.StringSlice("family")) == 0 {
+			return cli.NewExitError("oscalkit profile create requires --controls or --family", 1)
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) error {
+		ctlg, err := generator.FetchCatalog(context.Background(), catalogPath)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot read catalog, err: %v", err), 1)
+		}
+		href := importHref
+		if href == "" {
+			if href, err = defaultHref(catalogPath, outputFile); err != nil {
+				return cli.NewExitError(err, 1)
+			}
+		}
+
+		var ids []string
+		scheme := impl.DetectScheme(ctlg)
+		for _, list := range c.StringSlice("controls") {
+			for _, id := range strings.Split(list, ",") {
+				id = strings.TrimSpace(id)
+				if id == "" {
+					continue
+				}
+				if scheme != nil {
+					id = scheme.Normalize(id)
+				}
+				ids = append(ids, id)
+			}
+		}
+		b := oscalprofile.NewBuilder(profileID, profileTitle).ImportCatalog(href, ctlg)
+		if len(ids) > 0 {
+			b.IncludeIDs(withSubcontrols, ids...)
+		}
+		for _, family := range c.StringSlice("family") {
+			b.IncludeFamily(family, withSubcontrols)
+		}
+		p, err := b.Build()
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		var w io.Writer = os.Stdout
+		if outputFile != "" {
+			out, err := os.Create(filepath.Clean(outputFile))
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+			defer out.Close()
+			w = out
+		}
+		o := &oscal.OSCAL{Profile: p}
+		if isJSON {
+			err = o.JSON(w, true)
+		} else {
+			err = o.XML(w, true)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write profile, err: %v", err), 1)
+		}
+		return nil
+	},
+}
+
+// defaultHref gives the href of a catalog URL as is, and that of a catalog file relative to the
+// directory of the profile, which is the working directory when the profile goes to STDOUT
+func defaultHref(catalogPath, output string) (string, error) {
+	if u, err := url.Parse(catalogPath); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return catalogPath, nil
+	}
+	abs, err := filepath.Abs(catalogPath)
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Abs(filepath.Dir(output))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
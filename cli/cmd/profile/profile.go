@@ -0,0 +1,15 @@
+package profile
+
+import (
+	"github.com/urfave/cli"
+)
+
+// Profile cli command to author OSCAL profiles
+var Profile = cli.Command{
+	Name:  "profile",
+	Usage: "author OSCAL profiles",
+	Subcommands: []cli.Command{
+		Create,
+		Tailor,
+	},
+}
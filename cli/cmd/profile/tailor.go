@@ -0,0 +1,90 @@
+package profile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/oscalkit/types/oscal"
+	oscalprofile "github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/urfave/cli"
+)
+
+var overlayFile string
+var tailoredFile string
+
+// Tailor tailors a profile with a YAML overlay
+var Tailor = cli.Command{
+	Name:      "tailor",
+	Usage:     "tailor a profile with the controls, parameters and alters of a YAML overlay",
+	ArgsUsage: "[profile]",
+	Description: `Add or remove the control calls of an import of the profile, set parameter values and
+	 add alters as given by a YAML overlay file. The tailored profile is written in the format of
+	 the profile, XML, JSON or YAML, keeping the content oscalkit does not model, such as the
+	 publication information of baselines. It goes to STDOUT unless --output is given, which may
+	 be the profile itself.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:        "overlay",
+			Usage:       "YAML overlay file to apply",
+			Destination: &overlayFile,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "output file for the tailored profile. Defaults to STDOUT",
+			Destination: &tailoredFile,
+		},
+	},
+	Before: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return cli.NewExitError("oscalkit profile tailor requires a profile", 1)
+		}
+		if overlayFile == "" {
+			return cli.NewExitError("oscalkit profile tailor is missing the --overlay flag", 1)
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) error {
+		profilePath := filepath.Clean(c.Args().First())
+		raw, err := ioutil.ReadFile(profilePath)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot read profile, err: %v", err), 1)
+		}
+		o, err := oscal.New(bytes.NewReader(raw))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot parse profile, err: %v", err), 1)
+		}
+		if o.Profile == nil {
+			return cli.NewExitError(fmt.Sprintf("%s is not a profile", profilePath), 1)
+		}
+
+		f, err := os.Open(filepath.Clean(overlayFile))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot read overlay, err: %v", err), 1)
+		}
+		defer f.Close()
+		overlay, err := oscalprofile.ReadOverlay(f)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		if err := oscalprofile.Tailor(o.Profile, *overlay); err != nil {
+			return cli.NewExitError(err, 1)
+		}
+
+		var buf bytes.Buffer
+		if err := o.Rewrite(&buf, raw); err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write profile, err: %v", err), 1)
+		}
+		if tailoredFile == "" {
+			_, err = os.Stdout.Write(buf.Bytes())
+		} else {
+			err = ioutil.WriteFile(filepath.Clean(tailoredFile), buf.Bytes(), 0644)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write profile, err: %v", err), 1)
+		}
+		return nil
+	},
+}
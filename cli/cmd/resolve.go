@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/oscalkit/generator"
+	"github.com/docker/oscalkit/types/oscal"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var resolveOutput string
+var resolveJSON bool
+var resolveJSONEncoding string
+var maxImportDepth int
+var cacheDir string
+var offline bool
+var timeout time.Duration
+var explainID string
+var conflictPolicy string
+
+// Resolve resolves a profile into a single OSCAL catalog
+var Resolve = cli.Command{
+	Name:  "resolve",
+	Usage: "resolve a profile into a single OSCAL catalog",
+	Description: `Resolve the import chain of a profile and write the selected, altered and
+	 merged controls as one OSCAL catalog. Each control links back to its source catalog,
+	 the profile import which selected it and the profiles modifying it.`,
+	ArgsUsage: "[profile]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:        "output, o",
+			Usage:       "output file for the resolved catalog. Defaults to STDOUT",
+			Destination: &resolveOutput,
+		},
+		cli.BoolFlag{
+			Name:        "json, j",
+			Usage:       "write the resolved catalog as JSON instead of XML",
+			Destination: &resolveJSON,
+		},
+		cli.StringFlag{
+			Name:        "json-encoding",
+			Usage:       "encoding of the JSON catalog: legacy, with camelCase keys, or official, as the OSCAL JSON schemas",
+			Value:       string(oscal.LegacyJSON),
+			Destination: &resolveJSONEncoding,
+		},
+		cli.IntFlag{
+			Name:        "max-import-depth",
+			Usage:       "maximum number of imports followed from the profile down to a catalog",
+			Value:       generator.DefaultMaxImportDepth,
+			Destination: &maxImportDepth,
+		},
+		cli.StringFlag{
+			Name:        "cache-dir",
+			Usage:       "directory caching documents imported over http(s) and resolved catalogs",
+			Value:       generator.DefaultCacheDir(),
+			Destination: &cacheDir,
+		},
+		cli.BoolFlag{
+			Name:        "offline",
+			Usage:       "resolve http(s) imports from the cache only",
+			Destination: &offline,
+		},
+		cli.DurationFlag{
+			Name:        "timeout",
+			Usage:       "give up resolving after the given duration, such as 30s. No timeout by default",
+			Destination: &timeout,
+		},
+		cli.StringFlag{
+			Name:        "on-conflict",
+			Usage:       "what to do when profiles of the import chain alter the same item of a control or set a parameter differently: nearest-wins or fail",
+			Value:       generator.ConflictNearestWins,
+			Destination: &conflictPolicy,
+		},
+		cli.StringFlag{
+			Name:        "explain",
+			Usage:       "report how the control or subcontrol with the given id got its final form instead of writing the catalog",
+			Destination: &explainID,
+		},
+	},
+	Before: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return cli.NewExitError("oscalkit resolve requires a profile argument", 1)
+		}
+		switch oscal.JSONEncoding(resolveJSONEncoding) {
+		case oscal.LegacyJSON, oscal.OfficialJSON:
+		default:
+			return cli.NewExitError(fmt.Sprintf("unknown JSON encoding %s, expected legacy or official", resolveJSONEncoding), 1)
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) error {
+		profilePath, err := generator.GetAbsolutePath(c.Args().First())
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot get absolute path, err: %v", err), 1)
+		}
+		f, err := os.Open(profilePath)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		defer f.Close()
+
+		profile, err := generator.ReadProfile(f)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		profile, err = generator.SetBasePath(profile, profilePath)
+		if err != nil {
+			return cli.NewExitError(fmt.Errorf("failed to setup href path for profiles: %v", err), 1)
+		}
+
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		opts := generator.Options{
+			Href:           profilePath,
+			MaxImportDepth: maxImportDepth,
+			Fetcher:        generator.NewFetcher(cacheDir, offline),
+			ConflictPolicy: conflictPolicy,
+			Cache:          generator.NewCache(generator.ResolutionCacheDir(cacheDir)),
+		}
+
+		var explanation *generator.Explanation
+		var resolved *catalog.Catalog
+		if explainID != "" {
+			explanation, err = generator.Explain(ctx, profile, explainID, opts)
+		} else {
+			resolved, err = generator.Resolve(ctx, profile, opts)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot resolve profile, err: %v", err), 1)
+		}
+
+		// the output is only created once the profile is resolved
+		var w io.Writer = os.Stdout
+		if resolveOutput != "" {
+			out, err := os.Create(filepath.Clean(resolveOutput))
+			if err != nil {
+				return cli.NewExitError(err, 1)
+			}
+			defer out.Close()
+			w = out
+		}
+
+		if explanation != nil {
+			if resolveJSON {
+				e := json.NewEncoder(w)
+				e.SetIndent("", "  ")
+				err = e.Encode(explanation)
+			} else {
+				err = explanation.WriteText(w)
+			}
+			if err != nil {
+				return cli.NewExitError(fmt.Sprintf("cannot write explanation, err: %v", err), 1)
+			}
+			return nil
+		}
+
+		o := &oscal.OSCAL{Catalog: resolved}
+		if resolveJSON {
+			err = o.EncodeJSON(w, true, oscal.JSONEncoding(resolveJSONEncoding))
+		} else {
+			err = o.XML(w, true)
+		}
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("cannot write resolved catalog, err: %v", err), 1)
+		}
+		logrus.Debug("profile resolved")
+		return nil
+	},
+}
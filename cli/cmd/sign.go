@@ -19,7 +19,7 @@ var alg string
 // Sign ...
 var Sign = cli.Command{
 	Name:      "sign",
-	Usage:     "sign OSCAL JSON artifacts",
+	Usage:     "sign OSCAL JSON and YAML artifacts",
 	ArgsUsage: "[files...]",
 	Flags: []cli.Flag{
 		cli.StringFlag{
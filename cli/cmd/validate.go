@@ -15,7 +15,7 @@ var Validate = cli.Command{
 	Name:  "validate",
 	Usage: "validate files against OSCAL XML and JSON schemas",
 	Description: `Validate OSCAL-formatted XML files against a specific XML schema (.xsd)
-	 or OSCAL-formatted JSON files against a specific JSON schema`,
+	 or OSCAL-formatted JSON and YAML files against a specific JSON schema`,
 	ArgsUsage: "[files...]",
 	Flags: []cli.Flag{
 		cli.StringFlag{
@@ -38,8 +38,11 @@ var Validate = cli.Command{
 				return cli.NewExitError("Schema file should be .xsd", 1)
 			}
 
-			if filepath.Ext(f) == ".json" && filepath.Ext(schemaFile) != ".json" {
-				return cli.NewExitError("Schema file should be .json", 1)
+			switch filepath.Ext(f) {
+			case ".json", ".yaml", ".yml":
+				if filepath.Ext(schemaFile) != ".json" {
+					return cli.NewExitError("Schema file should be .json", 1)
+				}
 			}
 		}
 
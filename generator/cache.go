@@ -0,0 +1,276 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/docker/oscalkit/types/oscal"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/sirupsen/logrus"
+)
+
+// Cache memoizes the resolution of profiles. Documents of import chains are read once per
+// content, whichever href they are fetched from, and resolved catalogs are kept under the hash
+// of the profile, the content of every document of its import chain and the options changing
+// the outcome. With a directory, resolved catalogs are also kept on disk for later runs.
+//
+// A Cache is safe for concurrent use by several resolutions. Documents read through it are
+// shared, so they are never changed by the resolver, and resolved catalogs are copied in and
+// out of it.
+type Cache struct {
+	// dir keeps resolved catalogs on disk when not empty
+	dir string
+
+	mu        sync.Mutex
+	documents map[string]*cachedDocument
+	resolved  map[string][]*catalog.Catalog
+}
+
+// cachedDocument is a document read at most once, by the first resolution needing it
+type cachedDocument struct {
+	once sync.Once
+	o    *oscal.OSCAL
+	err  error
+}
+
+// NewCache returns a cache keeping resolutions in memory and, when dir is not empty, on disk
+// under dir
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:       dir,
+		documents: make(map[string]*cachedDocument),
+		resolved:  make(map[string][]*catalog.Catalog),
+	}
+}
+
+// ResolutionCacheDir is the directory keeping resolved catalogs within a directory caching
+// fetched documents, such as DefaultCacheDir
+func ResolutionCacheDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "resolved")
+}
+
+// documents fetches and reads the documents of import chains, through a cache when there is one
+type documents struct {
+	fetcher Fetcher
+	cache   *Cache
+}
+
+// read fetches and reads the OSCAL document at href, along with the hash of its content
+func (d documents) read(ctx context.Context, href string) (*oscal.OSCAL, string, error) {
+	r, err := d.fetcher.Fetch(ctx, href)
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+	if d.cache == nil {
+		o, err := oscal.New(bytes.NewReader(b))
+		return o, hash, err
+	}
+
+	d.cache.mu.Lock()
+	doc, ok := d.cache.documents[hash]
+	if !ok {
+		doc = &cachedDocument{}
+		d.cache.documents[hash] = doc
+	}
+	d.cache.mu.Unlock()
+	doc.once.Do(func() {
+		doc.o, doc.err = oscal.New(bytes.NewReader(b))
+	})
+	return doc.o, hash, doc.err
+}
+
+// resolutionKey hashes what resolving a profile depends on. kind tells apart the results of
+// resolving the same profile in different ways.
+func resolutionKey(ctx context.Context, kind string, p *profile.Profile, opts Options, docs documents) (string, error) {
+	policy, err := opts.conflictPolicy()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%d\n", kind, opts.Href, policy, opts.maxImportDepth())
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	h.Write(b)
+
+	visited := make(map[string]bool)
+	var hashImports func(p *profile.Profile) error
+	hashImports = func(p *profile.Profile) error {
+		for _, imp := range p.Imports {
+			if err := ValidateHref(imp.Href); err != nil {
+				return err
+			}
+			href := imp.Href.String()
+			if visited[href] {
+				continue
+			}
+			visited[href] = true
+			o, hash, err := docs.read(ctx, href)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s %s\n", href, hash)
+			if o.Profile == nil {
+				continue
+			}
+			imported, err := SetBasePath(o.Profile.DeepCopy(), href)
+			if err != nil {
+				return err
+			}
+			if err := hashImports(imported); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := hashImports(p); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// memoize gives the catalogs of a resolution from the cache of opts, resolving them with
+// resolveCatalogs on a miss. Without a cache, or when the import chain cannot be read, the
+// catalogs are resolved as they would be without one.
+func memoize(ctx context.Context, kind string, p *profile.Profile, opts Options, resolveCatalogs func() ([]*catalog.Catalog, error)) ([]*catalog.Catalog, error) {
+	c := opts.Cache
+	if c == nil {
+		return resolveCatalogs()
+	}
+	key, err := resolutionKey(ctx, kind, p, opts, opts.documents())
+	if err != nil {
+		logrus.Debugf("cannot cache the resolution of %s: %v", opts.Href, err)
+		return resolveCatalogs()
+	}
+	if catalogs, ok := c.lookup(key); ok {
+		logrus.Infof("using cached resolution of %s", opts.Href)
+		return catalogs, nil
+	}
+	catalogs, err := resolveCatalogs()
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, catalogs)
+	return catalogs, nil
+}
+
+// lookup gives copies of the catalogs kept under key, in memory or on disk
+func (c *Cache) lookup(key string) ([]*catalog.Catalog, bool) {
+	c.mu.Lock()
+	catalogs, ok := c.resolved[key]
+	c.mu.Unlock()
+	if !ok {
+		if c.dir == "" {
+			return nil, false
+		}
+		var err error
+		catalogs, err = readResolved(filepath.Join(c.dir, key))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.Warnf("ignoring cached resolution %s: %v", key, err)
+			}
+			return nil, false
+		}
+		c.mu.Lock()
+		c.resolved[key] = catalogs
+		c.mu.Unlock()
+	}
+	return copyCatalogs(catalogs), true
+}
+
+// store keeps copies of the catalogs under key, writing them to disk when the cache has a directory
+func (c *Cache) store(key string, catalogs []*catalog.Catalog) {
+	kept := copyCatalogs(catalogs)
+	c.mu.Lock()
+	c.resolved[key] = kept
+	c.mu.Unlock()
+	if c.dir == "" {
+		return
+	}
+	if err := writeResolved(c.dir, key, kept); err != nil {
+		logrus.Warnf("cannot cache resolution on disk: %v", err)
+	}
+}
+
+func copyCatalogs(catalogs []*catalog.Catalog) []*catalog.Catalog {
+	copies := make([]*catalog.Catalog, 0, len(catalogs))
+	for _, c := range catalogs {
+		copies = append(copies, c.DeepCopy())
+	}
+	return copies
+}
+
+// writeResolved writes catalogs as XML files numbered in order in a directory named after key.
+// The directory is written aside and renamed, so a resolution is either entirely on disk or not.
+func writeResolved(dir, key string, catalogs []*catalog.Catalog) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir(dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	for i, c := range catalogs {
+		var buf bytes.Buffer
+		if err := (&oscal.OSCAL{Catalog: c}).XML(&buf, false); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(tmp, strconv.Itoa(i)+".xml"), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	target := filepath.Join(dir, key)
+	if err := os.Rename(tmp, target); err != nil {
+		if _, statErr := os.Stat(target); statErr == nil {
+			// another run cached the same resolution meanwhile
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// readResolved reads the numbered catalogs of a resolution written by writeResolved
+func readResolved(dir string) ([]*catalog.Catalog, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	catalogs := []*catalog.Catalog{}
+	for i := 0; ; i++ {
+		f, err := os.Open(filepath.Join(dir, strconv.Itoa(i)+".xml"))
+		if os.IsNotExist(err) {
+			return catalogs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		o, err := oscal.New(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if o.Catalog == nil {
+			return nil, fmt.Errorf("%s is not a catalog", f.Name())
+		}
+		catalogs = append(catalogs, o.Catalog)
+	}
+}
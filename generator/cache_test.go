@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docker/oscalkit/types/oscal"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// tallyFetcher counts the documents fetched
+type tallyFetcher struct {
+	Fetcher
+	mu      sync.Mutex
+	fetched int
+}
+
+func (f *tallyFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.fetched++
+	f.mu.Unlock()
+	return f.Fetcher.Fetch(ctx, href)
+}
+
+func (f *tallyFetcher) reset() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fetched := f.fetched
+	f.fetched = 0
+	return fetched
+}
+
+func readBaseline(t *testing.T, fsys fstest.MapFS, name string) *profile.Profile {
+	p, err := ReadProfile(bytes.NewReader(fsys[name].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err = SetBasePath(p, "/"+name); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func catalogXML(t *testing.T, c *catalog.Catalog) string {
+	var buf bytes.Buffer
+	if err := (&oscal.OSCAL{Catalog: c}).XML(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCacheReusesResolutions(t *testing.T) {
+	fsys := baselineFS(t)
+	fetcher := &tallyFetcher{Fetcher: FSFetcher{FS: fsys}}
+	p := readBaseline(t, fsys, "FedRAMP_LOW-baseline_profile.xml")
+	opts := Options{Href: "/FedRAMP_LOW-baseline_profile.xml", Fetcher: fetcher, Cache: NewCache("")}
+
+	uncached, err := Resolve(context.Background(), p, Options{Href: opts.Href, Fetcher: fetcher})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher.reset()
+	first, err := Resolve(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolving := fetcher.reset()
+	// changes to a resolved catalog do not reach the cache
+	first.Title = "changed"
+	second, err := Resolve(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched := fetcher.reset(); fetched >= resolving {
+		t.Errorf("a cached resolution should only fetch the import chain to hash it, got %d fetches against %d", fetched, resolving)
+	}
+	if catalogXML(t, second) != catalogXML(t, uncached) {
+		t.Error("a cached resolution should be the one resolved without cache")
+	}
+	if len(opts.Cache.documents) != 2 {
+		t.Errorf("the NIST LOW baseline and the catalog it imports should be read once, got %d documents", len(opts.Cache.documents))
+	}
+
+	// another profile importing the same catalog reuses the parsed catalog
+	if _, err := ResolveImports(context.Background(), readBaseline(t, fsys, "NIST_SP-800-53_rev4_HIGH-baseline_profile.xml"), Options{
+		Href:    "/NIST_SP-800-53_rev4_HIGH-baseline_profile.xml",
+		Fetcher: fetcher,
+		Cache:   opts.Cache,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Cache.documents) != 2 {
+		t.Errorf("the NIST catalog should be read once for all profiles, got %d documents", len(opts.Cache.documents))
+	}
+}
+
+func TestCacheOnDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oscalkit-resolved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fsys := baselineFS(t)
+	p := readBaseline(t, fsys, "NIST_SP-800-53_rev4_LOW-baseline_profile.xml")
+	opts := Options{Href: "/NIST_SP-800-53_rev4_LOW-baseline_profile.xml", Fetcher: FSFetcher{FS: fsys}, Cache: NewCache(dir)}
+	resolved, err := ResolveImports(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a later run reads the resolution from disk
+	opts.Cache = NewCache(dir)
+	cached, err := ResolveImports(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Cache.resolved) != 1 || len(cached) != len(resolved) {
+		t.Fatalf("expected the resolution of %d catalogs to be read from disk, got %d", len(resolved), len(cached))
+	}
+	if catalogXML(t, cached[0]) != catalogXML(t, resolved[0]) {
+		t.Error("a resolution read from disk should be the one written")
+	}
+
+	// a change to the catalog makes for another resolution
+	name := "usnistgov/OSCAL/master/content/nist.gov/SP800-53/rev4/NIST_SP-800-53_rev4_catalog.xml"
+	fsys[name] = &fstest.MapFile{Data: bytes.Replace(fsys[name].Data, []byte("Access Control Policy and Procedures"), []byte("Policy and Procedures"), 1)}
+	changed, err := ResolveImports(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := string(changed[0].Groups[0].Controls[0].Title); title != "Policy and Procedures" {
+		t.Errorf("a changed catalog should be resolved again, got title %q", title)
+	}
+}
+
+func TestCacheConcurrentResolutions(t *testing.T) {
+	fsys := baselineFS(t)
+	cache := NewCache("")
+	names := []string{
+		"NIST_SP-800-53_rev4_LOW-baseline_profile.xml",
+		"NIST_SP-800-53_rev4_MODERATE-baseline_profile.xml",
+		"NIST_SP-800-53_rev4_HIGH-baseline_profile.xml",
+	}
+	profiles := make([]*profile.Profile, len(names))
+	for i, name := range names {
+		profiles[i] = readBaseline(t, fsys, name)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(names)*2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n := i % len(names)
+			_, errs[i] = Resolve(context.Background(), profiles[n], Options{Href: "/" + names[n], Fetcher: FSFetcher{FS: fsys}, Cache: cache})
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(cache.resolved) != len(names) {
+		t.Errorf("expected %d resolutions, got %d", len(names), len(cache.resolved))
+	}
+}
@@ -0,0 +1,270 @@
+package generator
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// Comparison reports how the resolved catalogs of two profiles, A and B, differ
+type Comparison struct {
+	A string `json:"a"`
+	B string `json:"b"`
+	// OnlyInA, OnlyInB and InBoth are control and subcontrol ids in catalog order
+	OnlyInA []string `json:"onlyInA"`
+	OnlyInB []string `json:"onlyInB"`
+	InBoth  []string `json:"inBoth"`
+	// Params and Alters differ between controls and subcontrols of both catalogs
+	Params []ParamDifference `json:"params,omitempty"`
+	Alters []AlterDifference `json:"alters,omitempty"`
+}
+
+// ParamDifference is a parameter whose resolved value differs between the profiles
+type ParamDifference struct {
+	ID string `json:"id"`
+	// Target is the control or subcontrol having the parameter
+	Target string `json:"target"`
+	A      string `json:"a"`
+	B      string `json:"b"`
+}
+
+// AlterDifference is a control or subcontrol altered differently by the profiles
+type AlterDifference struct {
+	Target string   `json:"target"`
+	A      []string `json:"a,omitempty"`
+	B      []string `json:"b,omitempty"`
+}
+
+// Compare resolves two profiles and reports the controls and subcontrols selected by only one of
+// them or by both, along with the parameter values and alters which differ for those in both.
+// Each profile is resolved with its own options, whose Href names it in the comparison.
+func Compare(ctx context.Context, a *profile.Profile, aOpts Options, b *profile.Profile, bOpts Options) (*Comparison, error) {
+	ra, err := resolve(ctx, a, aOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %v", profileName(a, aOpts), err)
+	}
+	rb, err := resolve(ctx, b, bOpts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %v", profileName(b, bOpts), err)
+	}
+	return compare(ra, rb, profileName(a, aOpts), profileName(b, bOpts)), nil
+}
+
+func profileName(p *profile.Profile, opts Options) string {
+	if opts.Href != "" {
+		return opts.Href
+	}
+	return p.ID
+}
+
+// resolvedItem is a control or subcontrol of a resolved catalog along with its parameters
+type resolvedItem struct {
+	id     string
+	params []catalog.Param
+}
+
+// resolvedItems lists the controls and subcontrols of a resolved catalog in catalog order
+func resolvedItems(c *catalog.Catalog) []resolvedItem {
+	var items []resolvedItem
+	catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, _ []catalog.Node) error {
+		switch {
+		case n.Control != nil:
+			items = append(items, resolvedItem{id: n.Control.Id, params: n.Control.Params})
+		case n.Subcontrol != nil:
+			items = append(items, resolvedItem{id: n.Subcontrol.Id, params: n.Subcontrol.Params})
+		case n.Part != nil:
+			return catalog.SkipChildren
+		}
+		return nil
+	}))
+	return items
+}
+
+func compare(a, b *resolution, aName, bName string) *Comparison {
+	cmp := &Comparison{A: aName, B: bName, OnlyInA: []string{}, OnlyInB: []string{}, InBoth: []string{}}
+	aItems, bItems := resolvedItems(a.catalog), resolvedItems(b.catalog)
+	inB := make(map[string]resolvedItem, len(bItems))
+	for _, item := range bItems {
+		inB[strings.ToLower(item.id)] = item
+	}
+	inA := make(map[string]bool, len(aItems))
+	for _, item := range aItems {
+		key := strings.ToLower(item.id)
+		inA[key] = true
+		bItem, ok := inB[key]
+		if !ok {
+			cmp.OnlyInA = append(cmp.OnlyInA, item.id)
+			continue
+		}
+		cmp.InBoth = append(cmp.InBoth, item.id)
+		cmp.Params = append(cmp.Params, paramDifferences(item, bItem)...)
+	}
+	for _, item := range bItems {
+		if !inA[strings.ToLower(item.id)] {
+			cmp.OnlyInB = append(cmp.OnlyInB, item.id)
+		}
+	}
+
+	aAlters, bAlters := altersByTarget(a.alters), altersByTarget(b.alters)
+	for _, id := range cmp.InBoth {
+		key := strings.ToLower(id)
+		if reflect.DeepEqual(aAlters[key], bAlters[key]) {
+			continue
+		}
+		cmp.Alters = append(cmp.Alters, AlterDifference{
+			Target: id,
+			A:      describeAlters(aAlters[key]),
+			B:      describeAlters(bAlters[key]),
+		})
+	}
+	return cmp
+}
+
+func paramDifferences(a, b resolvedItem) []ParamDifference {
+	bParams := make(map[string]catalog.Param, len(b.params))
+	for _, p := range b.params {
+		bParams[p.Id] = p
+	}
+	var diffs []ParamDifference
+	for _, p := range a.params {
+		bp, ok := bParams[p.Id]
+		if !ok {
+			continue
+		}
+		aValue, bValue := describeParam(p), describeParam(bp)
+		if aValue != bValue {
+			diffs = append(diffs, ParamDifference{ID: p.Id, Target: a.id, A: aValue, B: bValue})
+		}
+	}
+	return diffs
+}
+
+func describeParam(p catalog.Param) string {
+	var items []string
+	if p.Value != "" {
+		items = append(items, fmt.Sprintf("value %q", p.Value))
+	}
+	for _, c := range p.Constraints {
+		items = append(items, fmt.Sprintf("constraint %q", c.Value))
+	}
+	if len(items) == 0 {
+		return "no value"
+	}
+	return strings.Join(items, ", ")
+}
+
+// altersByTarget groups the applied alters by the control or subcontrol they target, leaving
+// out the profiles declaring them
+func altersByTarget(sourced []sourcedAlter) map[string][]profile.Alter {
+	byTarget := make(map[string][]profile.Alter)
+	for _, sa := range sourced {
+		alt := sa.alter
+		target := strings.ToLower(alterID(alt))
+		alt.ControlId, alt.SubcontrolId = "", ""
+		byTarget[target] = append(byTarget[target], alt)
+	}
+	return byTarget
+}
+
+func describeAlters(alters []profile.Alter) []string {
+	var descs []string
+	for _, alt := range alters {
+		for _, rm := range alt.Removals {
+			descs = append(descs, describeRemove(rm))
+		}
+		for _, add := range alt.Additions {
+			descs = append(descs, describeAddedItems(add))
+		}
+	}
+	return descs
+}
+
+// describeAddedItems details the props and parts of an addition, which tell alters apart better
+// than their counts
+func describeAddedItems(add profile.Add) string {
+	var items []string
+	for _, p := range add.Props {
+		items = append(items, fmt.Sprintf("prop %s=%s", p.Class, p.Value))
+	}
+	for _, p := range add.Parts {
+		item := "part"
+		if p.Class != "" {
+			item += " " + p.Class
+		}
+		if p.Id != "" {
+			item += " " + p.Id
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return describeAdd(add)
+	}
+	return fmt.Sprintf("%s (%s)", describeAdd(add), strings.Join(items, ", "))
+}
+
+// WriteText writes the comparison as a human readable report
+func (c *Comparison) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A: %s\nB: %s\n", c.A, c.B)
+	ids := func(title string, ids []string) {
+		fmt.Fprintf(&b, "\n%s (%d):\n", title, len(ids))
+		for _, id := range ids {
+			fmt.Fprintf(&b, "  %s\n", id)
+		}
+	}
+	ids("only in A", c.OnlyInA)
+	ids("only in B", c.OnlyInB)
+	ids("in both", c.InBoth)
+	fmt.Fprintf(&b, "\nparameters with different values (%d):\n", len(c.Params))
+	for _, p := range c.Params {
+		fmt.Fprintf(&b, "  %s on %s\n    A: %s\n    B: %s\n", p.ID, p.Target, p.A, p.B)
+	}
+	fmt.Fprintf(&b, "\ndifferent alters (%d):\n", len(c.Alters))
+	none := func(descs []string) string {
+		if len(descs) == 0 {
+			return "none"
+		}
+		return strings.Join(descs, "; ")
+	}
+	for _, a := range c.Alters {
+		fmt.Fprintf(&b, "  %s\n    A: %s\n    B: %s\n", a.Target, none(a.A), none(a.B))
+		if none(a.A) == none(a.B) {
+			b.WriteString("    (same items with different content)\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteCSV writes the comparison as CSV records of kind, id, target, A and B. Controls and
+// subcontrols are of kind control and tell with yes or no whether each profile selects them.
+// Parameters and alters give the values and alters of each profile.
+func (c *Comparison) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{{"kind", "id", "target", "a", "b"}}
+	for _, id := range c.OnlyInA {
+		records = append(records, []string{"control", id, "", "yes", "no"})
+	}
+	for _, id := range c.OnlyInB {
+		records = append(records, []string{"control", id, "", "no", "yes"})
+	}
+	for _, id := range c.InBoth {
+		records = append(records, []string{"control", id, "", "yes", "yes"})
+	}
+	for _, p := range c.Params {
+		records = append(records, []string{"param", p.ID, p.Target, p.A, p.B})
+	}
+	for _, a := range c.Alters {
+		records = append(records, []string{"alter", a.Target, a.Target, strings.Join(a.A, "; "), strings.Join(a.B, "; ")})
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// baselineFS serves the baseline profiles and the NIST catalog of test_util/artifacts, the NIST
+// catalog also at the path of its https URL imported by the NIST baselines
+func baselineFS(t *testing.T) fstest.MapFS {
+	paths, err := filepath.Glob("../test_util/artifacts/*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{}
+	for _, p := range paths {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[filepath.Base(p)] = &fstest.MapFile{Data: b}
+	}
+	fsys["usnistgov/OSCAL/master/content/nist.gov/SP800-53/rev4/NIST_SP-800-53_rev4_catalog.xml"] = fsys["NIST_SP-800-53_rev4_catalog.xml"]
+	return fsys
+}
+
+func compareBaselines(t *testing.T, fsys fstest.MapFS, a, b string) *Comparison {
+	read := func(name string) (*profile.Profile, Options) {
+		p, err := ReadProfile(bytes.NewReader(fsys[name].Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, err = SetBasePath(p, "/"+name); err != nil {
+			t.Fatal(err)
+		}
+		return p, Options{Href: "/" + name, Fetcher: FSFetcher{FS: fsys}}
+	}
+	pa, aOpts := read(a)
+	pb, bOpts := read(b)
+	cmp, err := Compare(context.Background(), pa, aOpts, pb, bOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmp
+}
+
+func containsID(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompareBaselines(t *testing.T) {
+	fsys := baselineFS(t)
+	tt := []struct {
+		a, b    string
+		onlyInA []string
+		onlyInB []string
+		inBoth  int
+		check   func(t *testing.T, cmp *Comparison)
+	}{
+		{
+			a:       "NIST_SP-800-53_rev4_HIGH-baseline_profile.xml",
+			b:       "NIST_SP-800-53_rev4_MODERATE-baseline_profile.xml",
+			onlyInA: []string{"ac-2.5", "ac-2.11", "ac-2.12", "ac-2.13", "ac-6.3"},
+			onlyInB: []string{"cm-7.4"},
+			inBoth:  260,
+			check: func(t *testing.T, cmp *Comparison) {
+				if len(cmp.Params) != 0 || len(cmp.Alters) != 0 {
+					t.Errorf("NIST baselines should not differ in parameters and alters, got %+v and %+v", cmp.Params, cmp.Alters)
+				}
+			},
+		},
+		{
+			a:       "NIST_SP-800-53_rev4_MODERATE-baseline_profile.xml",
+			b:       "NIST_SP-800-53_rev4_LOW-baseline_profile.xml",
+			onlyInA: []string{"ac-2.1", "ac-2.2", "ac-2.3", "ac-2.4", "ac-4"},
+			onlyInB: []string{},
+			inBoth:  124,
+		},
+		{
+			a:       "FedRAMP_HIGH-baseline_profile.xml",
+			b:       "FedRAMP_MODERATE-baseline_profile.xml",
+			onlyInA: []string{"ac-2.5", "ac-2.11", "ac-6.3", "ac-18.4", "ac-18.5"},
+			onlyInB: []string{"ca-8.1"},
+			inBoth:  268,
+			check: func(t *testing.T, cmp *Comparison) {
+				expected := ParamDifference{ID: "ac-1_prm_2", Target: "ac-1", A: `constraint "at least annually"`, B: `constraint "at least every 3 years"`}
+				if len(cmp.Params) == 0 || cmp.Params[0] != expected {
+					t.Errorf("expected first parameter difference %+v, got %+v", expected, cmp.Params)
+				}
+			},
+		},
+		{
+			a:       "FedRAMP_MODERATE-baseline_profile.xml",
+			b:       "FedRAMP_LOW-baseline_profile.xml",
+			onlyInA: []string{"ac-2.1", "ac-2.2", "ac-2.3", "ac-2.4", "ac-4"},
+			onlyInB: []string{},
+			inBoth:  124,
+			check: func(t *testing.T, cmp *Comparison) {
+				for _, a := range cmp.Alters {
+					if a.Target == "ra-3" {
+						return
+					}
+				}
+				t.Errorf("ra-3 guidance differs between FedRAMP MODERATE and LOW, got %+v", cmp.Alters)
+			},
+		},
+		{
+			a:       "FedRAMP_LOW-baseline_profile.xml",
+			b:       "NIST_SP-800-53_rev4_LOW-baseline_profile.xml",
+			onlyInA: []string{"si-16"},
+			onlyInB: []string{"sa-4.10"},
+			inBoth:  123,
+			check: func(t *testing.T, cmp *Comparison) {
+				// the guidance FedRAMP adds comes along with the priority of the NIST baseline it imports
+				expected := AlterDifference{
+					Target: "ac-8",
+					A:      []string{`add position="ending" 1 part (part guidance)`, `add position="starting" 1 prop (prop priority=P1)`},
+					B:      []string{`add position="starting" 1 prop (prop priority=P1)`},
+				}
+				for _, a := range cmp.Alters {
+					if a.Target == "ac-8" && !reflect.DeepEqual(a, expected) {
+						t.Errorf("expected alter difference %+v, got %+v", expected, a)
+					}
+				}
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			cmp := compareBaselines(t, fsys, tc.a, tc.b)
+			if cmp.A != "/"+tc.a || cmp.B != "/"+tc.b {
+				t.Errorf("comparison should name the profiles by href, got %s and %s", cmp.A, cmp.B)
+			}
+			if len(cmp.OnlyInA) < len(tc.onlyInA) || !reflect.DeepEqual(cmp.OnlyInA[:len(tc.onlyInA)], tc.onlyInA) {
+				t.Errorf("expected only in A to start with %v, got %v", tc.onlyInA, cmp.OnlyInA)
+			}
+			if !reflect.DeepEqual(cmp.OnlyInB, tc.onlyInB) {
+				t.Errorf("expected only in B %v, got %v", tc.onlyInB, cmp.OnlyInB)
+			}
+			if len(cmp.InBoth) != tc.inBoth {
+				t.Errorf("expected %d controls in both, got %d", tc.inBoth, len(cmp.InBoth))
+			}
+			for _, id := range cmp.OnlyInA {
+				if containsID(cmp.InBoth, id) {
+					t.Errorf("%s is reported both only in A and in both", id)
+				}
+			}
+			if tc.check != nil {
+				tc.check(t, cmp)
+			}
+		})
+	}
+}
+
+func TestComparisonOutput(t *testing.T) {
+	cmp := &Comparison{
+		A:       "a.xml",
+		B:       "b.xml",
+		OnlyInA: []string{"ac-2.1"},
+		OnlyInB: []string{"ac-3"},
+		InBoth:  []string{"ac-1", "ac-2"},
+		Params:  []ParamDifference{{ID: "ac-1_prm_1", Target: "ac-1", A: `value "yearly"`, B: "no value"}},
+		Alters:  []AlterDifference{{Target: "ac-2", A: []string{`add position="ending" 1 prop (prop priority=P1)`}}},
+	}
+
+	var text bytes.Buffer
+	if err := cmp.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"only in A (1):\n  ac-2.1\n",
+		"only in B (1):\n  ac-3\n",
+		"in both (2):\n  ac-1\n  ac-2\n",
+		"ac-1_prm_1 on ac-1\n    A: value \"yearly\"\n    B: no value\n",
+		"ac-2\n    A: add position=\"ending\" 1 prop (prop priority=P1)\n    B: none\n",
+	} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("text report should contain %q, got\n%s", expected, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := cmp.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"kind", "id", "target", "a", "b"},
+		{"control", "ac-2.1", "", "yes", "no"},
+		{"control", "ac-3", "", "no", "yes"},
+		{"control", "ac-1", "", "yes", "yes"},
+		{"control", "ac-2", "", "yes", "yes"},
+		{"param", "ac-1_prm_1", "ac-1", `value "yearly"`, "no value"},
+		{"alter", "ac-2", "ac-2", `add position="ending" 1 prop (prop priority=P1)`, ""},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("expected CSV records %v, got %v", expected, records)
+	}
+
+	b, err := json.Marshal(cmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read Comparison
+	if err := json.Unmarshal(b, &read); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&read, cmp) {
+		t.Errorf("JSON comparison should round trip, got %+v", read)
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// Kinds of conflicting modifications
+const (
+	// ConflictAlter is a control or subcontrol altered differently by several profiles
+	ConflictAlter = "alter"
+	// ConflictSetParam is a parameter set differently by several profiles
+	ConflictSetParam = "set-param"
+)
+
+// Conflict is a control, subcontrol or parameter modified differently by several profiles of an
+// import chain
+type Conflict struct {
+	// Kind is ConflictAlter or ConflictSetParam
+	Kind string
+	// Target is the id of the altered control or subcontrol, or of the set parameter
+	Target string
+	// Alters or SetParams are the competing modifications in precedence order
+	Alters    []CompetingAlter
+	SetParams []CompetingSetParam
+}
+
+// CompetingAlter is an alter along with the href of the profile declaring it
+type CompetingAlter struct {
+	Source string
+	Alter  profile.Alter
+}
+
+// CompetingSetParam is a parameter setting along with the href of the profile declaring it
+type CompetingSetParam struct {
+	Source   string
+	SetParam profile.SetParam
+}
+
+func (c Conflict) String() string {
+	var competing []string
+	for _, ca := range c.Alters {
+		var changes []string
+		for _, rm := range ca.Alter.Removals {
+			changes = append(changes, describeRemove(rm))
+		}
+		for _, add := range ca.Alter.Additions {
+			changes = append(changes, describeAdd(add))
+		}
+		competing = append(competing, fmt.Sprintf("%s (%s)", sourceName(ca.Source), strings.Join(changes, ", ")))
+	}
+	for _, cs := range c.SetParams {
+		competing = append(competing, fmt.Sprintf("%s (%s)", sourceName(cs.Source), describeSetParam(cs.SetParam)))
+	}
+	return fmt.Sprintf("%s %s is modified by %s", c.Kind, c.Target, strings.Join(competing, " and "))
+}
+
+// sourceName names the profile declaring a modification, the resolved profile having no href
+// when its location is not known
+func sourceName(source string) string {
+	if source == "" {
+		return "the resolved profile"
+	}
+	return source
+}
+
+func describeSetParam(sp profile.SetParam) string {
+	var items []string
+	if sp.Value != "" {
+		items = append(items, fmt.Sprintf("value %q", sp.Value))
+	}
+	for _, c := range sp.Constraints {
+		items = append(items, fmt.Sprintf("constraint %q", c.Value))
+	}
+	if sp.Label != "" {
+		items = append(items, fmt.Sprintf("label %q", sp.Label))
+	}
+	if len(items) == 0 {
+		return "no value"
+	}
+	return strings.Join(items, ", ")
+}
+
+// competition collects the removals and additions of the alters of a control or subcontrol, in
+// precedence order, keeping track of the items several profiles compete for
+type competition struct {
+	id string
+	// claims are the alters of single items kept so far along with their source, by item
+	claims    map[string]claim
+	reported  map[string]bool
+	competing []CompetingAlter
+}
+
+type claim struct {
+	source string
+	item   profile.Alter
+}
+
+func newCompetition(id string) *competition {
+	return &competition{id: id, claims: make(map[string]claim), reported: make(map[string]bool)}
+}
+
+// keep gives the removals and additions of an alter which do not compete with those kept before,
+// telling whether there are any
+func (c *competition) keep(sa sourcedAlter) (profile.Alter, bool) {
+	alt := profile.Alter{ControlId: sa.alter.ControlId, SubcontrolId: sa.alter.SubcontrolId}
+	removal := func(rm profile.Remove) profile.Alter {
+		item := alt
+		item.Removals = []profile.Remove{rm}
+		return item
+	}
+	for _, rm := range sa.alter.Removals {
+		key := strings.ToLower(fmt.Sprintf("remove/%s/%s/%s", rm.ItemName, rm.IdRef, rm.ClassRef))
+		if c.claim(key, sa.source, removal(rm)) {
+			alt.Removals = append(alt.Removals, rm)
+		}
+	}
+
+	for _, add := range sa.alter.Additions {
+		kept := profile.Add{Position: add.Position, References: add.References}
+		addition := func(a profile.Add) profile.Alter {
+			item := profile.Alter{ControlId: alt.ControlId, SubcontrolId: alt.SubcontrolId}
+			a.Position = add.Position
+			item.Additions = []profile.Add{a}
+			return item
+		}
+		if add.Title != "" && c.claim("title", sa.source, addition(profile.Add{Title: add.Title})) {
+			kept.Title = add.Title
+		}
+		for _, prop := range add.Props {
+			if c.claim(propKey(prop), sa.source, addition(profile.Add{Props: []catalog.Prop{prop}})) {
+				kept.Props = append(kept.Props, prop)
+			}
+		}
+		for _, link := range add.Links {
+			// links never compete, those added the same way are kept once
+			key := fmt.Sprintf("link/%s/%s/%s", link.Rel, hrefString(link.Href), link.Value)
+			if c.claim(key, sa.source, addition(profile.Add{Links: []catalog.Link{link}})) {
+				kept.Links = append(kept.Links, link)
+			}
+		}
+		for _, param := range add.Params {
+			if param.Id == "" || c.claim("param/"+strings.ToLower(param.Id), sa.source, addition(profile.Add{Params: []catalog.Param{param}})) {
+				kept.Params = append(kept.Params, param)
+			}
+		}
+		for _, part := range add.Parts {
+			if part.Id == "" || c.claim("part/"+strings.ToLower(part.Id), sa.source, addition(profile.Add{Parts: []catalog.Part{part}})) {
+				kept.Parts = append(kept.Parts, part)
+			}
+		}
+		if !reflect.DeepEqual(kept, profile.Add{Position: add.Position}) {
+			alt.Additions = append(alt.Additions, kept)
+		}
+	}
+	return alt, len(alt.Removals) > 0 || len(alt.Additions) > 0
+}
+
+// claim tells whether the alter of a single item from source is kept. It is unless the item was
+// altered by another profile before, reporting the competition unless both add the item the same way.
+func (c *competition) claim(key, source string, item profile.Alter) bool {
+	held, ok := c.claims[key]
+	switch {
+	case !ok:
+		c.claims[key] = claim{source: source, item: item}
+		return true
+	case held.source == source:
+		return true
+	case len(item.Additions) > 0 && reflect.DeepEqual(addedItems(held.item), addedItems(item)):
+		return false
+	}
+	if !c.reported[key] {
+		c.reported[key] = true
+		c.competing = append(c.competing, CompetingAlter{Source: held.source, Alter: held.item})
+	}
+	c.competing = append(c.competing, CompetingAlter{Source: source, Alter: item})
+	return false
+}
+
+// addedItems are the additions of an alter wherever they are added
+func addedItems(alt profile.Alter) []profile.Add {
+	var adds []profile.Add
+	for _, add := range alt.Additions {
+		add.Position = ""
+		adds = append(adds, add)
+	}
+	return adds
+}
+
+// propKey tells apart the props competing with each other: those of the same class, or of the
+// same id for props without a class. Other props only compete with the same prop.
+func propKey(prop catalog.Prop) string {
+	switch {
+	case prop.Class != "":
+		return "prop/class/" + strings.ToLower(prop.Class)
+	case prop.Id != "":
+		return "prop/id/" + strings.ToLower(prop.Id)
+	}
+	return "prop/value/" + prop.Value
+}
@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImportError is the failure to resolve an import of a profile
+type ImportError struct {
+	Href string
+	Err  error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("cannot resolve import %s: %v", e.Href, e.Err)
+}
+
+// Unwrap gives the cause of the failure
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates the failures of several imports of a profile
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d imports failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// ConflictError reports the conflicting modifications of an import chain resolved with ConflictFail
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, c.String())
+	}
+	return fmt.Sprintf("%d conflicting modifications: %s", len(e.Conflicts), strings.Join(msgs, "; "))
+}
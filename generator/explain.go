@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// Explanation reports how a control or subcontrol got its final form in a resolved profile
+type Explanation struct {
+	ID string `json:"id"`
+	// Resolved tells whether the resolved catalog has the control
+	Resolved  bool                  `json:"resolved"`
+	Imports   []ImportExplanation   `json:"imports"`
+	Alters    []AlterExplanation    `json:"alters,omitempty"`
+	SetParams []SetParamExplanation `json:"setParams,omitempty"`
+	// Conflicts are the competing modifications of the control, of which the first one applies
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// ImportExplanation reports how an import of the resolved profile treated the control
+type ImportExplanation struct {
+	Href string `json:"href"`
+	// Source is the catalog the import was resolved from
+	Source string `json:"source"`
+	// Found tells whether the source catalog has the control
+	Found      bool     `json:"found"`
+	Selected   bool     `json:"selected"`
+	SelectedBy []string `json:"selectedBy,omitempty"`
+	// Exclusions are all the exclusions of the import, ExcludedBy those matching the control
+	Exclusions []string `json:"exclusions,omitempty"`
+	ExcludedBy []string `json:"excludedBy,omitempty"`
+}
+
+// AlterExplanation is an alter applied to the control or to one of its subcontrols
+type AlterExplanation struct {
+	// Source is the profile declaring the alter
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Removals  []string `json:"removals,omitempty"`
+	Additions []string `json:"additions,omitempty"`
+}
+
+// SetParamExplanation is a parameter setting applied to the control or to one of its subcontrols
+type SetParamExplanation struct {
+	// Source is the profile declaring the parameter setting
+	Source      string   `json:"source"`
+	ParamID     string   `json:"paramId"`
+	Target      string   `json:"target"`
+	Value       string   `json:"value,omitempty"`
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// Explain resolves a profile and reports how the control or subcontrol with the given id got its
+// final form: the imports selecting or excluding it, and the alters and set-params applied to it.
+func Explain(ctx context.Context, profileArg *profile.Profile, id string, opts Options) (*Explanation, error) {
+	r, err := resolve(ctx, profileArg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return explain(r, id), nil
+}
+
+func explain(r *resolution, id string) *Explanation {
+	resolved := catalog.NewIndex(r.catalog)
+	e := &Explanation{ID: id, Resolved: hasID(resolved, id)}
+	// the targets of alters and set-params are the control and its subcontrols
+	targets := map[string]bool{strings.ToLower(id): true}
+	var params []targetParam
+	// the alters and set-params applied to the control are those along the import chains selecting it
+	var modifiedBy []string
+	for _, mi := range r.imports {
+		// the id may be written in another notation of the scheme of the import's catalog
+		key := mi.index.key(id)
+		targets[key] = true
+		e.Resolved = e.Resolved || hasID(resolved, key)
+		ie := ImportExplanation{
+			Href:       mi.profileImport.Href.String(),
+			Source:     mi.source,
+			Selected:   mi.selection.hasControl(key) || mi.selection.hasSubcontrol(key),
+			SelectedBy: mi.selection.includedBy[key],
+			Exclusions: describeExclusions(mi.profileImport.Exclude),
+			ExcludedBy: mi.selection.excludedBy[key],
+		}
+		if ctrl := mi.index.Control(key); ctrl != nil {
+			ie.Found = true
+			params = appendTargetParams(params, ctrl.Id, ctrl.Params)
+			for _, sc := range ctrl.Subcontrols {
+				targets[strings.ToLower(sc.Id)] = true
+				params = appendTargetParams(params, sc.Id, sc.Params)
+			}
+		}
+		if sc := mi.index.Subcontrol(key); sc != nil {
+			ie.Found = true
+			params = appendTargetParams(params, sc.Id, sc.Params)
+		}
+		if ie.Selected {
+			modifiedBy = append(modifiedBy, mi.modifiedBy...)
+		}
+		e.Imports = append(e.Imports, ie)
+	}
+	applied := modifications{alters: r.alters, setParams: r.setParams}.declaredBy(modifiedBy...)
+
+	for _, sa := range applied.alters {
+		target := sa.alter.ControlId
+		if target == "" {
+			target = sa.alter.SubcontrolId
+		}
+		if !targets[strings.ToLower(target)] {
+			continue
+		}
+		ae := AlterExplanation{Source: sa.source, Target: target}
+		for _, rm := range sa.alter.Removals {
+			ae.Removals = append(ae.Removals, describeRemove(rm))
+		}
+		for _, add := range sa.alter.Additions {
+			ae.Additions = append(ae.Additions, describeAdd(add))
+		}
+		e.Alters = append(e.Alters, ae)
+	}
+
+	seen := make(map[string]bool)
+	for _, ssp := range applied.setParams {
+		sp := ssp.setParam
+		for _, tp := range params {
+			seenKey := tp.target + "/" + tp.param
+			if tp.param != sp.Id || seen[seenKey] {
+				continue
+			}
+			seen[seenKey] = true
+			se := SetParamExplanation{
+				Source:  ssp.source,
+				ParamID: sp.Id,
+				Target:  tp.target,
+				Value:   string(sp.Value),
+			}
+			for _, c := range sp.Constraints {
+				se.Constraints = append(se.Constraints, c.Value)
+			}
+			e.SetParams = append(e.SetParams, se)
+		}
+	}
+
+	for _, c := range r.conflicts {
+		concerned := false
+		switch c.Kind {
+		case ConflictAlter:
+			concerned = targets[strings.ToLower(c.Target)]
+		case ConflictSetParam:
+			for _, tp := range params {
+				concerned = concerned || tp.param == c.Target
+			}
+		}
+		if concerned {
+			e.Conflicts = append(e.Conflicts, c.String())
+		}
+	}
+	return e
+}
+
+// targetParam is a parameter of the explained control or of one of its subcontrols
+type targetParam struct {
+	target string
+	param  string
+}
+
+func appendTargetParams(params []targetParam, target string, catalogParams []catalog.Param) []targetParam {
+	for _, p := range catalogParams {
+		params = append(params, targetParam{target: target, param: p.Id})
+	}
+	return params
+}
+
+// hasID tells whether a control or subcontrol of the indexed catalog has the id
+func hasID(index *catalog.Index, id string) bool {
+	return index.Control(id) != nil || index.Subcontrol(id) != nil
+}
+
+func describeExclusions(exclude *profile.Exclude) []string {
+	if exclude == nil {
+		return nil
+	}
+	var exclusions []string
+	for _, call := range exclude.IdSelectors {
+		exclusions = append(exclusions, describeCall(call))
+	}
+	for _, match := range exclude.PatternSelectors {
+		exclusions = append(exclusions, describeMatch(match))
+	}
+	return exclusions
+}
+
+func describeRemove(rm profile.Remove) string {
+	desc := "remove"
+	if rm.ItemName != "" {
+		desc += fmt.Sprintf(" item-name=%q", rm.ItemName)
+	}
+	if rm.IdRef != "" {
+		desc += fmt.Sprintf(" id-ref=%q", rm.IdRef)
+	}
+	if rm.ClassRef != "" {
+		desc += fmt.Sprintf(" class-ref=%q", rm.ClassRef)
+	}
+	return desc
+}
+
+func describeAdd(add profile.Add) string {
+	var items []string
+	if add.Title != "" {
+		items = append(items, fmt.Sprintf("title %q", add.Title))
+	}
+	count := func(n int, item string) {
+		switch {
+		case n == 1:
+			items = append(items, "1 "+item)
+		case n > 1:
+			items = append(items, fmt.Sprintf("%d %ss", n, item))
+		}
+	}
+	count(len(add.Props), "prop")
+	count(len(add.Links), "link")
+	count(len(add.Params), "param")
+	count(len(add.Parts), "part")
+	if add.References != nil {
+		items = append(items, "references")
+	}
+	return fmt.Sprintf("add position=%q %s", additionPosition(add), strings.Join(items, ", "))
+}
+
+// WriteText writes the explanation as a human readable report
+func (e *Explanation) WriteText(w io.Writer) error {
+	var b strings.Builder
+	status := "resolved"
+	if !e.Resolved {
+		status = "not in the resolved catalog"
+	}
+	fmt.Fprintf(&b, "%s: %s\n", e.ID, status)
+	for _, ie := range e.Imports {
+		fmt.Fprintf(&b, "import %s\n", ie.Href)
+		fmt.Fprintf(&b, "  source catalog: %s\n", ie.Source)
+		switch {
+		case !ie.Found:
+			b.WriteString("  not found in source catalog\n")
+		case ie.Selected:
+			fmt.Fprintf(&b, "  selected by: %s\n", strings.Join(ie.SelectedBy, "; "))
+		case len(ie.SelectedBy) > 0:
+			fmt.Fprintf(&b, "  selected by: %s\n", strings.Join(ie.SelectedBy, "; "))
+			fmt.Fprintf(&b, "  excluded by: %s\n", strings.Join(ie.ExcludedBy, "; "))
+		default:
+			b.WriteString("  not selected\n")
+		}
+		if len(ie.Exclusions) == 0 {
+			b.WriteString("  exclusions checked: none\n")
+		} else {
+			fmt.Fprintf(&b, "  exclusions checked: %s\n", strings.Join(ie.Exclusions, "; "))
+		}
+	}
+	for _, ae := range e.Alters {
+		fmt.Fprintf(&b, "alter %s from %s\n", ae.Target, ae.Source)
+		for _, desc := range append(append([]string{}, ae.Removals...), ae.Additions...) {
+			fmt.Fprintf(&b, "  %s\n", desc)
+		}
+	}
+	for _, se := range e.SetParams {
+		fmt.Fprintf(&b, "set-param %s on %s from %s", se.ParamID, se.Target, se.Source)
+		if se.Value != "" {
+			fmt.Fprintf(&b, ": value %q", se.Value)
+		}
+		for _, c := range se.Constraints {
+			fmt.Fprintf(&b, ": constraint %q", c)
+		}
+		b.WriteString("\n")
+	}
+	for _, c := range e.Conflicts {
+		fmt.Fprintf(&b, "conflict: %s\n", c)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
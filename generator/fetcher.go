@@ -0,0 +1,265 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/oscalkit/types/oscal"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/sirupsen/logrus"
+)
+
+// Fetcher fetches the documents referenced by the hrefs of profile imports
+type Fetcher interface {
+	Fetch(ctx context.Context, href string) (io.ReadCloser, error)
+}
+
+// NewFetcher returns a fetcher getting http(s) hrefs through an HTTPFetcher caching in cacheDir
+// and any other href from the local filesystem. An empty cacheDir disables caching.
+func NewFetcher(cacheDir string, offline bool) Fetcher {
+	return schemeFetcher{
+		http: HTTPFetcher{CacheDir: cacheDir, Offline: offline},
+		file: FileFetcher{},
+	}
+}
+
+// DefaultCacheDir is the directory caching fetched documents when none is configured
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "oscalkit")
+}
+
+// FetchCatalog reads the catalog at a path or URL, caching catalogs fetched over http(s) in the
+// default cache directory
+func FetchCatalog(ctx context.Context, href string) (*catalog.Catalog, error) {
+	r, err := NewFetcher(DefaultCacheDir(), false).Fetch(ctx, href)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	o, err := oscal.New(r)
+	if err != nil {
+		return nil, err
+	}
+	if o.Catalog == nil {
+		return nil, fmt.Errorf("%s is not a catalog", href)
+	}
+	return o.Catalog, nil
+}
+
+// schemeFetcher dispatches hrefs to a fetcher depending on their scheme
+type schemeFetcher struct {
+	http Fetcher
+	file Fetcher
+}
+
+func (f schemeFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	uri, err := url.Parse(href)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %v", err)
+	}
+	if isHTTPResource(uri) {
+		return f.http.Fetch(ctx, href)
+	}
+	return f.file.Fetch(ctx, href)
+}
+
+// FileFetcher fetches documents from the local filesystem
+type FileFetcher struct{}
+
+// Fetch opens the file at href, which is either a path or a file URL
+func (FileFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	uri, err := url.Parse(href)
+	if err == nil && uri.Scheme == "file" {
+		href = uri.Path
+	}
+	return os.Open(href)
+}
+
+// FSFetcher fetches documents from a filesystem such as an embed.FS. The path of an href is taken
+// relative to the root of the filesystem whatever its scheme and host, so a profile read from
+// the filesystem should have its base path set to /<name of the profile>.
+type FSFetcher struct {
+	FS fs.FS
+}
+
+// Fetch opens the file of the filesystem at the path of href
+func (f FSFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	uri, err := url.Parse(href)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %v", err)
+	}
+	return f.FS.Open(strings.TrimPrefix(path.Clean("/"+uri.Path), "/"))
+}
+
+// HTTPFetcher fetches documents over http(s). With a cache directory, documents are stored
+// under the hash of their URL and revalidated with ETag and Last-Modified on later fetches.
+type HTTPFetcher struct {
+	// Client defaults to a client with a 10 seconds timeout
+	Client *http.Client
+	// CacheDir is where fetched documents are cached. Caching is disabled when empty.
+	CacheDir string
+	// Offline only serves documents from the cache
+	Offline bool
+}
+
+// cacheEntry holds the validators of a cached document
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Fetch gets the document at href, from the cache when it is still valid
+func (f HTTPFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	if f.CacheDir == "" {
+		if f.Offline {
+			return nil, fmt.Errorf("cannot fetch %s offline without a cache directory", href)
+		}
+		resp, err := f.get(ctx, href, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("cannot fetch from url %s: %s", href, resp.Status)
+		}
+		return resp.Body, nil
+	}
+
+	content, meta := f.cachePaths(href)
+	entry, cached := readCacheEntry(content, meta)
+	if f.Offline {
+		if !cached {
+			return nil, fmt.Errorf("%s is not cached and cannot be fetched offline", href)
+		}
+		logrus.Debugf("fetching %s from cache", href)
+		return os.Open(content)
+	}
+
+	t := time.Now()
+	resp, err := f.get(ctx, href, entry)
+	if err != nil {
+		if cached {
+			logrus.Warnf("cannot revalidate %s, using cached copy: %v", href, err)
+			return os.Open(content)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		logrus.Debugf("cached copy of %s is up to date", href)
+		return os.Open(content)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("cannot fetch from url %s: %s", href, resp.Status)
+	}
+
+	if err := os.MkdirAll(f.CacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory %v", err)
+	}
+	if err := writeCacheFile(f.CacheDir, content, resp.Body); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(cacheEntry{
+		URL:          href,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCacheFile(f.CacheDir, meta, strings.NewReader(string(b))); err != nil {
+		return nil, err
+	}
+	logrus.Debugf("file downloaded in %f seconds.", time.Since(t).Seconds())
+	return os.Open(content)
+}
+
+func (f HTTPFetcher) get(ctx context.Context, href string, entry *cacheEntry) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, href, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %v", err)
+	}
+	req = req.WithContext(ctx)
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	client := f.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	logrus.Debugf("fetching from http resource %s", href)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch from url %v", err)
+	}
+	return resp, nil
+}
+
+// cachePaths gives the cache files holding the content and the validators of a document.
+// Files are named after the hash of the URL and keep its extension.
+func (f HTTPFetcher) cachePaths(href string) (content string, meta string) {
+	sum := sha256.Sum256([]byte(href))
+	name := hex.EncodeToString(sum[:])
+	if uri, err := url.Parse(href); err == nil {
+		name += path.Ext(uri.Path)
+	}
+	content = filepath.Join(f.CacheDir, name)
+	return content, content + ".meta.json"
+}
+
+// readCacheEntry reads the validators of a cached document, reporting whether it is cached at all
+func readCacheEntry(content, meta string) (*cacheEntry, bool) {
+	if _, err := os.Stat(content); err != nil {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	b, err := ioutil.ReadFile(meta)
+	if err != nil {
+		return nil, true
+	}
+	if err := json.Unmarshal(b, entry); err != nil {
+		logrus.Warnf("ignoring invalid cache metadata %s: %v", meta, err)
+		return nil, true
+	}
+	return entry, true
+}
+
+// writeCacheFile replaces a cache file atomically so concurrent fetches never read partial content
+func writeCacheFile(dir, name string, r io.Reader) error {
+	tmp, err := ioutil.TempFile(dir, ".fetch-")
+	if err != nil {
+		return fmt.Errorf("cannot create cache file %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write cache file %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write cache file %v", err)
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	nistLowProfile = "../test_util/artifacts/NIST_SP-800-53_rev4_LOW-baseline_profile.xml"
+	nistCatalog    = "../test_util/artifacts/NIST_SP-800-53_rev4_catalog.xml"
+)
+
+func fetchString(t *testing.T, f Fetcher, href string) (string, error) {
+	r, err := f.Fetch(context.Background(), href)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), nil
+}
+
+func TestHTTPFetcherCache(t *testing.T) {
+	requests, revalidated := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := fmt.Sprintf(`"%s"`, r.URL.Path)
+		if r.Header.Get("If-None-Match") == etag {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	dir, err := ioutil.TempDir("", "oscalkit-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := HTTPFetcher{CacheDir: dir}
+	// same base name under different URLs must not collide
+	for _, p := range []string{"/a/catalog.xml", "/b/catalog.xml", "/a/catalog.xml"} {
+		body, err := fetchString(t, f, server.URL+p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != p {
+			t.Errorf("fetching %s got %s", p, body)
+		}
+	}
+	if requests != 3 || revalidated != 1 {
+		t.Errorf("cached document should be revalidated, got %d requests and %d revalidations", requests, revalidated)
+	}
+
+	server.Close()
+	f.Offline = true
+	body, err := fetchString(t, f, server.URL+"/b/catalog.xml")
+	if err != nil || body != "/b/catalog.xml" {
+		t.Errorf("offline fetch should be served from cache, got %s, %v", body, err)
+	}
+	if _, err := fetchString(t, f, server.URL+"/c/catalog.xml"); err == nil {
+		t.Error("offline fetch of a document missing from cache should fail")
+	}
+}
+
+func TestResolveWithFSFetcher(t *testing.T) {
+	profileXML, err := ioutil.ReadFile(nistLowProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalogXML, err := ioutil.ReadFile(nistCatalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the NIST LOW baseline imports the catalog over https, which is served from the FS by its path
+	fsys := fstest.MapFS{
+		"low.xml": &fstest.MapFile{Data: profileXML},
+		"usnistgov/OSCAL/master/content/nist.gov/SP800-53/rev4/NIST_SP-800-53_rev4_catalog.xml": &fstest.MapFile{Data: catalogXML},
+	}
+	p, err := ReadProfile(bytes.NewReader(profileXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = SetBasePath(p, "/low.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := Resolve(context.Background(), p, Options{Href: "/low.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the baseline is merged as-is so controls keep their groups
+	if len(resolved.Groups) == 0 || resolved.Groups[0].Controls[0].Id != "ac-1" {
+		t.Error("NIST LOW baseline should be resolved from the FS")
+	}
+}
@@ -2,16 +2,29 @@ package generator
 
 import (
 	"bytes"
+	"context"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
-	"github.com/docker/oscalkit/impl"
+	"github.com/docker/oscalkit/types/oscal"
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
 )
 
 const (
+	fedrampHighProfile                 = "../test_util/artifacts/FedRAMP_HIGH-baseline_profile.xml"
 	temporaryFilePathForCatalogJSON    = "/tmp/catalog.json"
 	temporaryFilePathForProfileJSON    = "/tmp/profile.json"
 	temporaryFilePathForCatalogsGoFile = "/tmp/catalogs.go"
@@ -280,7 +293,7 @@ func TestProcessAdditionWithSameClass(t *testing.T) {
 				profile.Add{
 					Parts: []catalog.Part{
 						catalog.Part{
-							Id:    partID,
+							Id:    partID + "_added",
 							Class: class,
 						},
 					},
@@ -293,7 +306,7 @@ func TestProcessAdditionWithSameClass(t *testing.T) {
 				profile.Add{
 					Parts: []catalog.Part{
 						catalog.Part{
-							Id:    partID,
+							Id:    partID + "_added",
 							Class: class,
 						},
 					},
@@ -331,22 +344,113 @@ func TestProcessAdditionWithSameClass(t *testing.T) {
 	}
 
 	o := ProcessAlterations(alters, &c)
-	for _, g := range o.Groups {
-		for _, c := range g.Controls {
-			for i := range c.Parts {
-				expected := fmt.Sprintf("%s_%d", partID, i+1)
-				if c.Parts[i].Id != expected {
-					t.Errorf("%s and %s are not identical", c.Parts[i].Id, expected)
-					return
-				}
-			}
-			for i, sc := range c.Subcontrols {
-				expected := fmt.Sprintf("%s_%d", partID, i+1)
-				if sc.Parts[i].Id != expected {
-					t.Errorf("%s and %s are not identical", sc.Parts[i].Id, expected)
-					return
-				}
+	expected := []string{partID, partID + "_added"}
+	ctrl := o.Groups[0].Controls[0]
+	if fmt.Sprint(partIDs(ctrl.Parts)) != fmt.Sprint(expected) {
+		t.Errorf("control parts %v, expected %v", partIDs(ctrl.Parts), expected)
+	}
+	if fmt.Sprint(partIDs(ctrl.Subcontrols[0].Parts)) != fmt.Sprint(expected) {
+		t.Errorf("subcontrol parts %v, expected %v", partIDs(ctrl.Subcontrols[0].Parts), expected)
+	}
+}
+
+func partIDs(parts []catalog.Part) []string {
+	var ids []string
+	for _, p := range parts {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
+
+func TestProcessAdditionPositions(t *testing.T) {
+	newControl := func() []catalog.Control {
+		return []catalog.Control{
+			catalog.Control{
+				Id:    "ac-1",
+				Title: "Access Control Policy and Procedures",
+				Props: []catalog.Prop{catalog.Prop{Class: "label", Value: "AC-1"}},
+				Parts: []catalog.Part{
+					catalog.Part{Id: "ac-1_smt", Class: "statement"},
+					catalog.Part{Id: "ac-1_gdn", Class: "guidance"},
+					catalog.Part{Id: "ac-1_obj", Class: "objective"},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		position string
+		parts    []string
+		props    []string
+	}{
+		{PositionStarting, []string{"new_1", "new_2", "ac-1_smt", "ac-1_gdn", "ac-1_obj"}, []string{"P1", "AC-1"}},
+		{PositionEnding, []string{"ac-1_smt", "ac-1_gdn", "ac-1_obj", "new_1", "new_2"}, []string{"AC-1", "P1"}},
+		{"", []string{"ac-1_smt", "ac-1_gdn", "ac-1_obj", "new_1", "new_2"}, []string{"AC-1", "P1"}},
+		// content beside the control has nowhere to go in the catalog
+		{PositionBefore, []string{"ac-1_smt", "ac-1_gdn", "ac-1_obj"}, []string{"AC-1"}},
+		{PositionAfter, []string{"ac-1_smt", "ac-1_gdn", "ac-1_obj"}, []string{"AC-1"}},
+	}
+	for _, tt := range tests {
+		alt := profile.Alter{
+			ControlId: "ac-1",
+			Additions: []profile.Add{
+				profile.Add{
+					Position: tt.position,
+					Title:    "Policy",
+					Props:    []catalog.Prop{catalog.Prop{Class: "priority", Value: "P1"}},
+					Params:   []catalog.Param{catalog.Param{Id: "ac-1_prm_9"}},
+					References: &catalog.References{
+						Refs: []catalog.Ref{catalog.Ref{Id: "ref-1"}},
+					},
+					Parts: []catalog.Part{
+						catalog.Part{Id: "new_1", Class: "guidance"},
+						catalog.Part{Id: "new_2", Class: "guidance"},
+					},
+				},
+			},
+		}
+		ctrl := ProcessAddition(alt, newControl())[0]
+		if fmt.Sprint(partIDs(ctrl.Parts)) != fmt.Sprint(tt.parts) {
+			t.Errorf("%s: parts %v, expected %v", tt.position, partIDs(ctrl.Parts), tt.parts)
+		}
+		var props []string
+		for _, p := range ctrl.Props {
+			props = append(props, p.Value)
+		}
+		if fmt.Sprint(props) != fmt.Sprint(tt.props) {
+			t.Errorf("%s: props %v, expected %v", tt.position, props, tt.props)
+		}
+		if !insidePosition(tt.position) && tt.position != "" {
+			if ctrl.Title == "Policy" || len(ctrl.Params) != 0 || ctrl.References != nil {
+				t.Errorf("%s: nothing should be added", tt.position)
 			}
+			continue
+		}
+		if ctrl.Title != "Policy" {
+			t.Errorf("%s: title should be replaced", tt.position)
+		}
+		if len(ctrl.Params) != 1 || ctrl.References == nil || len(ctrl.References.Refs) != 1 {
+			t.Errorf("%s: params and references should be added", tt.position)
+		}
+	}
+}
+
+func TestResolveSkipsAdditionsBesideTarget(t *testing.T) {
+	for _, position := range []string{PositionBefore, PositionAfter} {
+		p := importsProfile(t, "/catalog.xml")
+		p.Modify = &profile.Modify{Alterations: []profile.Alter{profile.Alter{
+			ControlId: "ac-1",
+			Additions: []profile.Add{
+				profile.Add{Position: position, Props: []catalog.Prop{catalog.Prop{Class: "priority", Value: "P1"}}},
+				profile.Add{Props: []catalog.Prop{catalog.Prop{Class: "baseline", Value: "low"}}},
+			},
+		}}}
+		c, err := Resolve(context.Background(), p, Options{Fetcher: FSFetcher{FS: selectionTestFS(t)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctrl := catalog.NewIndex(c).Control("ac-1")
+		if ctrl == nil || len(ctrl.Props) != 1 || ctrl.Props[0].Value != "low" {
+			t.Errorf("additions %s the target should be skipped and the others applied, got %v", position, ctrl)
 		}
 	}
 }
@@ -442,6 +546,12 @@ func TestProcessSetParam(t *testing.T) {
 	controls := []catalog.Control{
 		catalog.Control{
 			Id: ctrl,
+			Params: []catalog.Param{
+				catalog.Param{
+					Id:    parameterID,
+					Label: "organization-defined personnel or roles",
+				},
+			},
 			Parts: []catalog.Part{
 				catalog.Part{
 					Prose: &catalog.Prose{
@@ -462,8 +572,18 @@ func TestProcessSetParam(t *testing.T) {
 			},
 		},
 	}
-	nc := impl.NISTCatalog{}
-	ctlg = ProcessSetParam(sp, ctlg, &nc)
+	ctlg = ProcessSetParam(sp, ctlg)
+	param := ctlg.Groups[0].Controls[0].Params[0]
+	if len(param.Constraints) != 1 || param.Constraints[0].Value != parameterVal {
+		t.Error("failed to set constraint of parameter")
+	}
+	if param.Label != "organization-defined personnel or roles" {
+		t.Error("label should be kept when not set")
+	}
+	if ctlg.Groups[0].Controls[0].Parts[0].Prose.P[0].Raw != shouldChange {
+		t.Error("insert markers should be kept in resolution")
+	}
+	ctlg = InsertParamValues(ctlg)
 	if ctlg.Groups[0].Controls[0].Parts[0].Prose.P[0].Raw != afterChange {
 		t.Error("failed to parse set param template")
 	}
@@ -488,6 +608,12 @@ func TestProcessSetParamWithUnmatchParam(t *testing.T) {
 	controls := []catalog.Control{
 		catalog.Control{
 			Id: ctrl,
+			Params: []catalog.Param{
+				catalog.Param{
+					Id:    parameterID,
+					Label: "organization-defined personnel or roles",
+				},
+			},
 			Parts: []catalog.Part{
 				catalog.Part{
 					Prose: &catalog.Prose{
@@ -508,15 +634,1419 @@ func TestProcessSetParamWithUnmatchParam(t *testing.T) {
 			},
 		},
 	}
-	nc := impl.NISTCatalog{}
-	ctlg = ProcessSetParam(sp, ctlg, &nc)
+	ctlg = InsertParamValues(ProcessSetParam(sp, ctlg))
 	if ctlg.Groups[0].Controls[0].Parts[0].Prose.P[0].Raw == afterChange {
 		t.Error("should not change parameter with mismatching parameter id")
 	}
 }
 
-func failTest(err error, t *testing.T) {
+func TestProcessSetParamInGroupsAndSubcontrols(t *testing.T) {
+	sp := []profile.SetParam{
+		profile.SetParam{
+			Id:    "grp_prm_1",
+			Value: "group value",
+		},
+		profile.SetParam{
+			Id:     "ac-2.1_prm_1",
+			Label:  "new label",
+			Select: &catalog.Select{HowMany: "one", Alternatives: []catalog.Choice{"a", "b"}},
+			Parts: []catalog.Part{
+				catalog.NewPart("", "", "guidance for the parameter"),
+			},
+			Links: []catalog.Link{catalog.Link{Rel: "reference", Value: "FedRAMP"}},
+		},
+	}
+	ctlg := &catalog.Catalog{
+		Groups: []catalog.Group{
+			catalog.Group{
+				Params: []catalog.Param{catalog.Param{Id: "grp_prm_1"}},
+				Controls: []catalog.Control{
+					catalog.Control{
+						Id: "ac-2",
+						Subcontrols: []catalog.Subcontrol{
+							catalog.Subcontrol{
+								Id: "ac-2.1",
+								Params: []catalog.Param{
+									catalog.Param{
+										Id:    "ac-2.1_prm_1",
+										Label: "old label",
+										Links: []catalog.Link{catalog.Link{Rel: "reference", Value: "NIST"}},
+									},
+								},
+								Parts: []catalog.Part{
+									catalog.NewPart("ac-2.1_smt", "", `select <insert param-id="ac-2.1_prm_1"/>`),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctlg = ProcessSetParam(sp, ctlg)
+	if ctlg.Groups[0].Params[0].Value != "group value" {
+		t.Error("failed to set value of group parameter")
+	}
+	param := ctlg.Groups[0].Controls[0].Subcontrols[0].Params[0]
+	if param.Label != "new label" || param.Select == nil || len(param.Select.Alternatives) != 2 {
+		t.Error("failed to set label and select of subcontrol parameter")
+	}
+	if len(param.Guidance) != 1 || len(param.Links) != 2 {
+		t.Error("failed to set guidance and merge links of subcontrol parameter")
+	}
+	ctlg = InsertParamValues(ctlg)
+	if ctlg.Groups[0].Controls[0].Subcontrols[0].Parts[0].Prose.P[0].Raw != `select <insert param-id="ac-2.1_prm_1"/>` {
+		t.Error("parameters without value should be left as insert markers")
+	}
+	ctlg.Groups[0].Controls[0].Subcontrols[0].Params[0].Value = "a"
+	ctlg = InsertParamValues(ctlg)
+	if ctlg.Groups[0].Controls[0].Subcontrols[0].Parts[0].Prose.P[0].Raw != "select a" {
+		t.Error("failed to render self-closing insert marker")
+	}
+}
+
+func selectionTestCatalog() *catalog.Catalog {
+	return &catalog.Catalog{
+		Title: "selection",
+		Groups: []catalog.Group{
+			catalog.Group{
+				Title: "Access Control",
+				Controls: []catalog.Control{
+					catalog.Control{
+						Id: "ac-1",
+					},
+					catalog.Control{
+						Id: "ac-2",
+						Subcontrols: []catalog.Subcontrol{
+							catalog.Subcontrol{Id: "ac-2.1"},
+							catalog.Subcontrol{Id: "ac-2.2"},
+						},
+					},
+				},
+			},
+			catalog.Group{
+				Title: "Audit and Accountability",
+				Controls: []catalog.Control{
+					catalog.Control{
+						Id: "au-1",
+						Subcontrols: []catalog.Subcontrol{
+							catalog.Subcontrol{Id: "au-1.1"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func mappedIDs(c catalog.Catalog) []string {
+	var ids []string
+	for _, g := range c.Groups {
+		for _, ctrl := range g.Controls {
+			ids = append(ids, ctrl.Id)
+			for _, sc := range ctrl.Subcontrols {
+				ids = append(ids, sc.Id)
+			}
+		}
+	}
+	return ids
+}
+
+func TestGetMappedCatalogControlsFromImportSelectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		imp      profile.Import
+		expected []string
+	}{
+		{
+			name:     "no include",
+			imp:      profile.Import{},
+			expected: []string{"ac-1", "ac-2", "ac-2.1", "ac-2.2", "au-1", "au-1.1"},
+		},
+		{
+			name: "all without subcontrols",
+			imp: profile.Import{
+				Include: &profile.Include{All: &profile.All{WithSubcontrols: "no"}},
+			},
+			expected: []string{"ac-1", "ac-2", "au-1"},
+		},
+		{
+			name: "all minus exclusions",
+			imp: profile.Import{
+				Include: &profile.Include{All: &profile.All{WithSubcontrols: "yes"}},
+				Exclude: &profile.Exclude{
+					IdSelectors:      []profile.Call{profile.Call{ControlId: "au-1"}, profile.Call{SubcontrolId: "ac-2.2"}},
+					PatternSelectors: []profile.Match{profile.Match{Pattern: "ac-1"}},
+				},
+			},
+			expected: []string{"ac-2", "ac-2.1"},
+		},
+		{
+			name: "call with subcontrols",
+			imp: profile.Import{
+				Include: &profile.Include{
+					IdSelectors: []profile.Call{profile.Call{ControlId: "ac-2", WithSubcontrols: "yes"}},
+				},
+			},
+			expected: []string{"ac-2", "ac-2.1", "ac-2.2"},
+		},
+		{
+			name: "subcontrol call without control",
+			imp: profile.Import{
+				Include: &profile.Include{
+					IdSelectors: []profile.Call{profile.Call{SubcontrolId: "ac-2.1", WithControl: "no"}, profile.Call{SubcontrolId: "au-1.1"}},
+				},
+			},
+			expected: []string{"au-1", "au-1.1"},
+		},
+		{
+			name: "match",
+			imp: profile.Import{
+				Include: &profile.Include{
+					PatternSelectors: []profile.Match{profile.Match{Pattern: `ac-\d+\.\d+`}},
+				},
+			},
+			expected: []string{"ac-2", "ac-2.1", "ac-2.2"},
+		},
+		{
+			name: "match with subcontrols",
+			imp: profile.Import{
+				Include: &profile.Include{
+					PatternSelectors: []profile.Match{profile.Match{Pattern: "au-.*", WithSubcontrols: "yes"}},
+				},
+			},
+			expected: []string{"au-1", "au-1.1"},
+		},
+		{
+			name: "calls in label notation of the catalog's scheme",
+			imp: profile.Import{
+				Include: &profile.Include{
+					IdSelectors: []profile.Call{profile.Call{ControlId: "AC-02"}, profile.Call{SubcontrolId: "AC-2 (1)"}},
+				},
+				Exclude: &profile.Exclude{
+					IdSelectors: []profile.Call{profile.Call{SubcontrolId: "AC-2(1)"}},
+				},
+			},
+			expected: []string{"ac-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := GetMappedCatalogControlsFromImport(selectionTestCatalog(), tt.imp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := mappedIDs(c)
+			if fmt.Sprint(ids) != fmt.Sprint(tt.expected) {
+				t.Errorf("mapped %v, expected %v", ids, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetMappedCatalogControlsFromImportWithInvalidSelectors(t *testing.T) {
+	imports := []profile.Import{
+		profile.Import{
+			Include: &profile.Include{
+				IdSelectors: []profile.Call{profile.Call{ControlId: "ac-9"}},
+			},
+		},
+		profile.Import{
+			Include: &profile.Include{
+				IdSelectors: []profile.Call{profile.Call{SubcontrolId: "ac-2.9"}},
+			},
+		},
+		profile.Import{
+			Include: &profile.Include{
+				PatternSelectors: []profile.Match{profile.Match{Pattern: "ac-(1"}},
+			},
+		},
+	}
+	for _, imp := range imports {
+		if _, err := GetMappedCatalogControlsFromImport(selectionTestCatalog(), imp); err == nil {
+			t.Error("error should not be nil")
+		}
+	}
+}
+
+func mergeTestCatalogs() []*catalog.Catalog {
+	return []*catalog.Catalog{
+		&catalog.Catalog{
+			Title: "first",
+			Groups: []catalog.Group{
+				catalog.Group{
+					Title: "Access Control",
+					Controls: []catalog.Control{
+						catalog.Control{
+							Id:    "ac-1",
+							Parts: []catalog.Part{catalog.Part{Id: "ac-1_smt"}},
+						},
+						catalog.Control{Id: "ac-2"},
+					},
+				},
+			},
+		},
+		&catalog.Catalog{
+			Title: "second",
+			Groups: []catalog.Group{
+				catalog.Group{
+					Title: "Access Control",
+					Controls: []catalog.Control{
+						catalog.Control{
+							Id:          "ac-1",
+							Parts:       []catalog.Part{catalog.Part{Id: "ac-1_gdn"}},
+							Subcontrols: []catalog.Subcontrol{catalog.Subcontrol{Id: "ac-1.1"}},
+						},
+					},
+				},
+				catalog.Group{
+					Title: "Audit and Accountability",
+					Controls: []catalog.Control{
+						catalog.Control{Id: "au-1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMergeCatalogsCombine(t *testing.T) {
+	tests := []struct {
+		method   string
+		controls int
+		parts    int
+	}{
+		{CombineUseFirst, 3, 1},
+		{CombineMerge, 3, 2},
+		{CombineKeep, 4, 1},
+	}
+	for _, tt := range tests {
+		merge := &profile.Merge{Combine: &profile.Combine{Method: tt.method}}
+		c, err := MergeCatalogs(merge, mergeTestCatalogs())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.Groups) != 0 {
+			t.Errorf("%s: controls should not be grouped without as-is or custom", tt.method)
+		}
+		if len(c.Controls) != tt.controls {
+			t.Errorf("%s: expected %d controls, got %d", tt.method, tt.controls, len(c.Controls))
+		}
+		if len(c.Controls[0].Parts) != tt.parts {
+			t.Errorf("%s: expected %d parts in ac-1, got %d", tt.method, tt.parts, len(c.Controls[0].Parts))
+		}
+	}
+	_, err := MergeCatalogs(&profile.Merge{Combine: &profile.Combine{Method: "unknown"}}, mergeTestCatalogs())
+	if err == nil {
+		t.Error("error should not be nil for unsupported combine method")
+	}
+}
+
+func TestMergeCatalogsAsIs(t *testing.T) {
+	p, err := ReadProfile(bytes.NewReader([]byte(`
+	<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<merge>
+			<as-is/>
+		</merge>
+	</profile>`)))
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+	c, err := MergeCatalogs(p.Merge, mergeTestCatalogs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(c.Groups))
+	}
+	if len(c.Groups[0].Controls) != 2 || len(c.Groups[1].Controls) != 1 {
+		t.Error("controls not structured as in source catalogs")
+	}
+}
+
+func TestMergeCatalogsCustom(t *testing.T) {
+	merge := &profile.Merge{
+		Combine: &profile.Combine{Method: CombineMerge},
+		Custom: &profile.Custom{
+			IdSelectors: []profile.Call{profile.Call{ControlId: "au-1"}},
+			Groups: []profile.Group{
+				profile.Group{
+					IdSelectors: []profile.Call{profile.Call{SubcontrolId: "ac-1.1"}},
+					Groups: []profile.Group{
+						profile.Group{
+							PatternSelectors: []profile.Match{profile.Match{Pattern: "ac-2"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	c, err := MergeCatalogs(merge, mergeTestCatalogs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Controls) != 1 || c.Controls[0].Id != "au-1" {
+		t.Error("au-1 should be placed at top level")
+	}
+	if len(c.Groups) != 1 || len(c.Groups[0].Controls) != 1 || c.Groups[0].Controls[0].Id != "ac-1" {
+		t.Fatal("ac-1 should be placed in custom group")
+	}
+	if len(c.Groups[0].Groups) != 1 || c.Groups[0].Groups[0].Controls[0].Id != "ac-2" {
+		t.Error("ac-2 should be placed in nested custom group")
+	}
+}
+
+func TestMergeCatalogsCustomMatchOrder(t *testing.T) {
+	catalogs := []*catalog.Catalog{
+		&catalog.Catalog{
+			Groups: []catalog.Group{
+				catalog.Group{
+					Controls: []catalog.Control{
+						catalog.Control{Id: "ac-10"},
+						catalog.Control{Id: "ac-2"},
+						catalog.Control{Id: "ac-1"},
+						catalog.Control{Id: "ac-2.1"},
+					},
+				},
+			},
+		},
+	}
+	tt := []struct {
+		order    string
+		expected []string
+	}{
+		{order: "", expected: []string{"ac-10", "ac-2", "ac-1", "ac-2.1"}},
+		{order: OrderKeep, expected: []string{"ac-10", "ac-2", "ac-1", "ac-2.1"}},
+		{order: OrderAscending, expected: []string{"ac-1", "ac-2", "ac-2.1", "ac-10"}},
+		{order: OrderDescending, expected: []string{"ac-10", "ac-2.1", "ac-2", "ac-1"}},
+	}
+	for _, tc := range tt {
+		merge := &profile.Merge{
+			Custom: &profile.Custom{
+				PatternSelectors: []profile.Match{profile.Match{Pattern: "ac-.*", Order: tc.order}},
+			},
+		}
+		c, err := MergeCatalogs(merge, catalogs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, ctrl := range c.Controls {
+			ids = append(ids, ctrl.Id)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tc.expected) {
+			t.Errorf("order %q: expected %v, got %v", tc.order, tc.expected, ids)
+		}
+	}
+}
+
+func nestedTestCatalog() *catalog.Catalog {
+	return &catalog.Catalog{
+		Title:    "Nested",
+		Controls: []catalog.Control{catalog.Control{Id: "top-1"}},
+		Groups: []catalog.Group{
+			catalog.Group{
+				Id:     "fam",
+				Class:  "family",
+				Title:  "Family",
+				Props:  []catalog.Prop{catalog.Prop{Class: "label", Value: "FAM"}},
+				Params: []catalog.Param{catalog.Param{Id: "fam_prm_1"}},
+				Parts:  []catalog.Part{catalog.Part{Id: "fam_smt", Class: "overview"}},
+				Controls: []catalog.Control{
+					catalog.Control{Id: "fam-1"},
+				},
+				Groups: []catalog.Group{
+					catalog.Group{
+						Id:    "sub",
+						Title: "Subfamily",
+						Controls: []catalog.Control{
+							catalog.Control{
+								Id:          "sub-1",
+								Subcontrols: []catalog.Subcontrol{catalog.Subcontrol{Id: "sub-1.1"}},
+							},
+							catalog.Control{Id: "sub-2"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetMappedCatalogControlsFromNestedGroups(t *testing.T) {
+	c := ProcessAlterations([]profile.Alter{
+		profile.Alter{ControlId: "top-1", Additions: []profile.Add{profile.Add{Title: "Top"}}},
+		profile.Alter{SubcontrolId: "sub-1.1", Additions: []profile.Add{profile.Add{Title: "Nested"}}},
+	}, nestedTestCatalog())
+	mapped, err := GetMappedCatalogControlsFromImport(c, profile.Import{
+		Include: &profile.Include{
+			IdSelectors: []profile.Call{
+				profile.Call{ControlId: "top-1"},
+				profile.Call{SubcontrolId: "sub-1.1"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapped.Controls) != 1 || mapped.Controls[0].Title != "Top" {
+		t.Error("top level controls of the catalog should be mapped and altered")
+	}
+	if len(mapped.Groups) != 1 {
+		t.Fatalf("expected 1 group, got %d", len(mapped.Groups))
+	}
+	fam := mapped.Groups[0]
+	if fam.Id != "fam" || fam.Class != "family" || len(fam.Props) != 1 || len(fam.Params) != 1 || len(fam.Parts) != 1 {
+		t.Error("group metadata should be kept")
+	}
+	if len(fam.Controls) != 0 {
+		t.Error("controls which are not selected should not be mapped")
+	}
+	if len(fam.Groups) != 1 || len(fam.Groups[0].Controls) != 1 || fam.Groups[0].Controls[0].Id != "sub-1" {
+		t.Fatal("controls of nested groups should be mapped")
+	}
+	sub1 := fam.Groups[0].Controls[0]
+	if len(sub1.Subcontrols) != 1 || sub1.Subcontrols[0].Title != "Nested" {
+		t.Error("subcontrols of nested groups should be mapped and altered")
+	}
+
+	merged, err := MergeCatalogs(&profile.Merge{AsIs: "true"}, []*catalog.Catalog{&mapped})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Controls) != 1 || merged.Controls[0].Id != "top-1" {
+		t.Error("controls without group should stay at top level as-is")
+	}
+	if len(merged.Groups) != 1 || merged.Groups[0].Id != "fam" || len(merged.Groups[0].Groups) != 1 ||
+		merged.Groups[0].Groups[0].Id != "sub" || merged.Groups[0].Groups[0].Controls[0].Id != "sub-1" {
+		t.Error("nested groups should be kept as-is")
+	}
+}
+
+func readArtifactProfile(t *testing.T, artifact string) *profile.Profile {
+	path, err := filepath.Abs(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	p, err := ReadProfile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = SetBasePath(p, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func findControl(catalogs []*catalog.Catalog, id string) *catalog.Control {
+	for _, c := range catalogs {
+		for i := range c.Groups {
+			for j := range c.Groups[i].Controls {
+				if c.Groups[i].Controls[j].Id == id {
+					return &c.Groups[i].Controls[j]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func findPart(parts []catalog.Part, class string) bool {
+	for _, part := range parts {
+		if part.Class == class || findPart(part.Parts, class) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestProcessRemovalWithFedRAMPProfile(t *testing.T) {
+	p := readArtifactProfile(t, fedrampHighProfile)
+	p.Modify.Alterations = append(p.Modify.Alterations,
+		profile.Alter{
+			ControlId: "ac-2",
+			Removals: []profile.Remove{
+				profile.Remove{ClassRef: "guidance"},
+				profile.Remove{ItemName: "part", ClassRef: "objects"},
+			},
+		},
+		profile.Alter{
+			SubcontrolId: "ac-2.1",
+			Removals: []profile.Remove{
+				profile.Remove{ItemName: "prop"},
+				profile.Remove{IdRef: "ac-2.1_obj"},
+			},
+		},
+	)
+	catalogs, err := CreateCatalogsFromProfile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctrl := findControl(catalogs, "ac-2")
+	if ctrl == nil {
+		t.Fatal("ac-2 should be mapped")
+	}
+	if findPart(ctrl.Parts, "guidance") {
+		t.Error("guidance part of ac-2 should be removed")
+	}
+	if findPart(ctrl.Parts, "objects") {
+		t.Error("nested objects parts of ac-2 should be removed")
+	}
+	if !findPart(ctrl.Parts, "statement") || !findPart(ctrl.Parts, "assessment") {
+		t.Error("parts not targeted by removals should be kept")
+	}
+	if !findPart(ctrl.Parts, "justification") {
+		t.Error("FedRAMP additions should still be processed")
+	}
+	for _, sc := range ctrl.Subcontrols {
+		if sc.Id != "ac-2.1" {
+			if len(sc.Props) == 0 {
+				t.Errorf("props of %s should be kept", sc.Id)
+			}
+			continue
+		}
+		if len(sc.Props) != 0 {
+			t.Error("props of ac-2.1 should be removed")
+		}
+		for _, part := range sc.Parts {
+			if part.Id == "ac-2.1_obj" {
+				t.Error("ac-2.1_obj should be removed")
+			}
+		}
+	}
+}
+
+func TestProcessRemovalWithoutTarget(t *testing.T) {
+	controls := []catalog.Control{
+		catalog.Control{
+			Id:    "ac-1",
+			Title: "Access Control Policy and Procedures",
+			Props: []catalog.Prop{catalog.Prop{Class: "label", Value: "AC-1"}},
+			Parts: []catalog.Part{catalog.Part{Id: "ac-1_smt", Class: "statement"}},
+		},
+	}
+	alt := profile.Alter{
+		ControlId: "ac-1",
+		Removals:  []profile.Remove{profile.Remove{}, profile.Remove{ItemName: "title"}},
+	}
+	controls = ProcessRemoval(alt, controls)
+	if controls[0].Title != "" {
+		t.Error("title should be removed")
+	}
+	if len(controls[0].Props) != 1 || len(controls[0].Parts) != 1 {
+		t.Error("a remove without item-name, id-ref or class-ref should not remove anything")
+	}
+}
+
+func TestResolveProvenance(t *testing.T) {
+	href, err := filepath.Abs(fedrampHighProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := Resolve(context.Background(), readArtifactProfile(t, fedrampHighProfile), Options{Href: href})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&oscal.OSCAL{Catalog: resolved}).XML(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	o, err := oscal.New(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Catalog == nil {
+		t.Fatal("resolved profile should be read back as a catalog")
+	}
+
+	var ctrl *catalog.Control
+	for i := range o.Catalog.Controls {
+		if o.Catalog.Controls[i].Id == "ac-2" {
+			ctrl = &o.Catalog.Controls[i]
+		}
+	}
+	if ctrl == nil {
+		t.Fatal("ac-2 should be resolved")
+	}
+	rels := make(map[string][]catalog.Link)
+	for _, link := range ctrl.Links {
+		rels[link.Rel] = append(rels[link.Rel], link)
+	}
+	if len(rels[RelSourceCatalog]) != 1 || filepath.Base(rels[RelSourceCatalog][0].Href.Path) != "NIST_SP-800-53_rev4_catalog.xml" {
+		t.Errorf("ac-2 should link to its source catalog, got %v", rels[RelSourceCatalog])
+	}
+	if len(rels[RelImport]) != 1 {
+		t.Errorf("ac-2 should link to the import which selected it, got %v", rels[RelImport])
+	}
+	// FedRAMP adds guidance to ac-2 and the NIST baseline it imports a priority
+	if len(rels[RelAlter]) != 2 || rels[RelAlter][0].Href.Path != href || filepath.Base(rels[RelAlter][1].Href.Path) != "NIST_SP-800-53_rev4_HIGH-baseline_profile.xml" {
+		t.Errorf("ac-2 should link to the profiles altering it, got %v", rels[RelAlter])
+	}
+	setParam := false
+	for _, link := range rels[RelSetParam] {
+		if link.Value == "ac-2_prm_4" && link.Href.Path == href {
+			setParam = true
+		}
+	}
+	if !setParam {
+		t.Errorf("ac-2 should link to the profile setting ac-2_prm_4, got %v", rels[RelSetParam])
+	}
+}
+
+func writeImportingProfile(t *testing.T, dir, name string, imports ...string) string {
+	var buf bytes.Buffer
+	buf.WriteString(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">`)
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, `<import href="%s"/>`, imp)
+	}
+	buf.WriteString(`</profile>`)
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveWithImportCycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oscalkit-cycle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := writeImportingProfile(t, dir, "a.xml", "b.xml")
+	b := writeImportingProfile(t, dir, "b.xml", "a.xml")
+	self := writeImportingProfile(t, dir, "self.xml", "self.xml")
+
+	tt := []struct {
+		profile string
+		cycle   string
+	}{
+		{profile: a, cycle: strings.Join([]string{a, b, a}, " -> ")},
+		{profile: self, cycle: strings.Join([]string{self, self}, " -> ")},
+	}
+	for _, tc := range tt {
+		_, err := Resolve(context.Background(), readArtifactProfile(t, tc.profile), Options{Href: tc.profile})
+		if err == nil {
+			t.Errorf("resolving %s should fail on the import cycle", tc.profile)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.cycle) {
+			t.Errorf("error should name the cycle %s, got %v", tc.cycle, err)
+		}
+	}
+	if _, err := CreateCatalogsFromProfile(readArtifactProfile(t, a)); err == nil {
+		t.Error("creating catalogs should fail on the import cycle")
+	}
+}
+
+func TestResolveProfileImportingFromParentDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oscalkit-relative")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name string, o *oscal.OSCAL) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := o.XML(f, true); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ctlg := selectionTestCatalog()
+	write(filepath.Join("catalogs", "catalog.xml"), &oscal.OSCAL{Catalog: ctlg})
+	// the href profile create writes for a catalog outside of the directory of the profile
+	p, err := profile.NewBuilder("relative", "relative").ImportCatalog("../catalogs/catalog.xml", ctlg).IncludeIDs(false, "au-1").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	href := write(filepath.Join("profiles", "profile.xml"), &oscal.OSCAL{Profile: p})
+
+	c, err := Resolve(context.Background(), readArtifactProfile(t, href), Options{Href: href})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := resolvedIDs(c); !reflect.DeepEqual(ids, []string{"au-1"}) {
+		t.Errorf("expected au-1 to be resolved from the catalog in the parent directory, got %v", ids)
+	}
+}
+
+func TestResolveWithMaxImportDepth(t *testing.T) {
+	href, err := filepath.Abs(fedrampHighProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FedRAMP imports the NIST baseline which in turn imports the catalog
+	_, err = Resolve(context.Background(), readArtifactProfile(t, fedrampHighProfile), Options{Href: href, MaxImportDepth: 1})
+	if err == nil || !strings.Contains(err.Error(), "maximum import depth of 1") {
+		t.Errorf("resolution deeper than the maximum import depth should fail, got %v", err)
+	}
+	if _, err = Resolve(context.Background(), readArtifactProfile(t, fedrampHighProfile), Options{Href: href, MaxImportDepth: 2}); err != nil {
+		t.Error(err)
+	}
+}
+
+// resolvedIDs lists the ids of the controls and subcontrols of a resolved catalog
+func resolvedIDs(c *catalog.Catalog) []string {
+	var ids []string
+	for _, ctrl := range catalog.NewIndex(c).Controls() {
+		ids = append(ids, ctrl.Id)
+		for _, sc := range ctrl.Subcontrols {
+			ids = append(ids, sc.Id)
+		}
+	}
+	return ids
+}
+
+func TestResolveTailoredBaseline(t *testing.T) {
+	fsys := baselineFS(t)
+	p := readBaseline(t, fsys, "FedRAMP_MODERATE-baseline_profile.xml")
+	overlay := profile.Overlay{Remove: profile.OverlaySelection{Controls: []string{"ac-2"}, Subcontrols: []string{"ac-17.1"}}}
+	if err := profile.Tailor(p, overlay); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Resolve(context.Background(), p, Options{Href: "/FedRAMP_MODERATE-baseline_profile.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := resolvedIDs(c)
+	for _, id := range ids {
+		if id == "ac-2" || strings.HasPrefix(id, "ac-2.") || id == "ac-17.1" {
+			t.Errorf("removed %s should not be resolved", id)
+		}
+	}
+	if !strings.Contains(fmt.Sprint(ids), " ac-17 ") {
+		t.Error("ac-17 should be kept without its removed subcontrol")
+	}
+}
+
+func TestResolveImportedProfileSelection(t *testing.T) {
+	catalogXML, err := xml.Marshal(selectionTestCatalog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"catalog.xml": &fstest.MapFile{Data: catalogXML},
+		"mid.xml": &fstest.MapFile{Data: []byte(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+			<import href="catalog.xml">
+				<include><call control-id="ac-2" with-subcontrols="yes"/><call control-id="au-1"/></include>
+			</import>
+			<merge><as-is>true</as-is></merge>
+			<modify><alter control-id="ac-2"><add><prop class="mid">added</prop></add></alter></modify>
+		</profile>`)},
+	}
+	p, err := ReadProfile(strings.NewReader(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<import href="/mid.xml">
+			<include><all with-subcontrols="yes"/></include>
+			<exclude><call control-id="au-1"/></exclude>
+		</import>
+	</profile>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := Resolve(context.Background(), p, Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all of the imported profile is its own selection, not the whole catalog
+	if ids := fmt.Sprint(resolvedIDs(resolved)); ids != "[ac-2 ac-2.1 ac-2.2]" {
+		t.Errorf("expected the controls selected by the imported profile less the excluded ones, got %s", ids)
+	}
+	ac2 := catalog.NewIndex(resolved).Control("ac-2")
+	if ac2 == nil || len(ac2.Props) != 1 || ac2.Props[0].Class != "mid" {
+		t.Errorf("alters of the imported profile should apply, got %+v", ac2)
+	}
+
+	// all of the NIST HIGH baseline less ac-1
+	baseline, err := filepath.Abs("../test_util/artifacts/NIST_SP-800-53_rev4_HIGH-baseline_profile.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := Resolve(context.Background(), readArtifactProfile(t, baseline), Options{Href: baseline})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err = ReadProfile(strings.NewReader(fmt.Sprintf(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<import href="%s">
+			<include><all with-subcontrols="yes"/></include>
+			<exclude><call control-id="ac-1"/></exclude>
+		</import>
+	</profile>`, baseline)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	highLessAC1, err := Resolve(context.Background(), p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := resolvedIDs(high)[1:]
+	if actual := resolvedIDs(highLessAC1); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the %d controls and subcontrols of HIGH less ac-1, got %d", len(expected), len(actual))
+	}
+}
+
+func TestResolveImportedProfileWithSeveralImports(t *testing.T) {
+	catalogXML, err := xml.Marshal(selectionTestCatalog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"a.xml": &fstest.MapFile{Data: catalogXML},
+		"b.xml": &fstest.MapFile{Data: catalogXML},
+		"mid.xml": &fstest.MapFile{Data: []byte(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+			<import href="b.xml"><include><call control-id="au-1"/></include></import>
+			<import href="a.xml"><include><call control-id="ac-1"/></include></import>
+		</profile>`)},
+	}
+	p := importsProfile(t, "/mid.xml")
+	e, err := Explain(context.Background(), p, "ac-1", Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := Resolve(context.Background(), p, Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the controls of both imports come in the order of the imported profile
+	if ids := fmt.Sprint(resolvedIDs(resolved)); ids != "[au-1 ac-1]" {
+		t.Errorf("expected the controls of every import of the imported profile, got %s", ids)
+	}
+	if !e.Resolved || e.Imports[0].Source != "/mid.xml" {
+		t.Errorf("controls of several catalogs should be sourced from the profile importing them, got %+v", e.Imports)
+	}
+}
+
+// delayedFetcher holds back the fetch of some hrefs
+type delayedFetcher struct {
+	Fetcher
+	delays map[string]time.Duration
+}
+
+func (f delayedFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	time.Sleep(f.delays[href])
+	return f.Fetcher.Fetch(ctx, href)
+}
+
+func TestMapImportsInImportOrder(t *testing.T) {
+	catalogXML, err := ioutil.ReadFile(nistCatalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"a.xml": &fstest.MapFile{Data: catalogXML},
+		"b.xml": &fstest.MapFile{Data: catalogXML},
+	}
+	imp := func(href, controlID string) profile.Import {
+		u, err := url.Parse(href)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return profile.Import{
+			Href:    &catalog.Href{URL: u},
+			Include: &profile.Include{IdSelectors: []profile.Call{profile.Call{ControlId: controlID}}},
+		}
+	}
+	p := &profile.Profile{Imports: []profile.Import{imp("/a.xml", "ac-1"), imp("/b.xml", "ac-2")}}
+	// the first import finishes last
+	fetcher := delayedFetcher{
+		Fetcher: FSFetcher{FS: fsys},
+		delays:  map[string]time.Duration{"/a.xml": 200 * time.Millisecond},
+	}
+	mapped, err := mapImports(context.Background(), p, modifications{}, newImportChain("", Options{}), Options{Fetcher: fetcher})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapped) != 2 || mapped[0].source != "/a.xml" || mapped[1].source != "/b.xml" {
+		t.Fatal("imports should be mapped in the order of the profile")
+	}
+	if mapped[0].catalog.Groups[0].Controls[0].Id != "ac-1" || mapped[1].catalog.Groups[0].Controls[0].Id != "ac-2" {
+		t.Error("catalogs should be mapped for their own import")
+	}
+}
+
+func TestResolveIsDeterministic(t *testing.T) {
+	href, err := filepath.Abs(fedrampHighProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first []byte
+	for i := 0; i < 3; i++ {
+		resolved, err := Resolve(context.Background(), readArtifactProfile(t, fedrampHighProfile), Options{Href: href})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := xml.Marshal(resolved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = b
+			continue
+		}
+		if !bytes.Equal(first, b) {
+			t.Fatal("resolving the same profile should give the same catalog")
+		}
+	}
+}
+
+// countingFetcher records the highest number of fetches in flight at once
+type countingFetcher struct {
+	Fetcher
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, href string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.max {
+		f.max = f.inFlight
+	}
+	f.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return f.Fetcher.Fetch(ctx, href)
+}
+
+func importsProfile(t *testing.T, hrefs ...string) *profile.Profile {
+	p := &profile.Profile{}
+	for _, href := range hrefs {
+		u, err := url.Parse(href)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Imports = append(p.Imports, profile.Import{Href: &catalog.Href{URL: u}})
+	}
+	return p
+}
+
+func selectionTestFS(t *testing.T) fstest.MapFS {
+	b, err := xml.Marshal(selectionTestCatalog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fstest.MapFS{"catalog.xml": &fstest.MapFile{Data: b}}
+}
+
+func TestResolveBoundsConcurrency(t *testing.T) {
+	p := importsProfile(t, "/catalog.xml", "/catalog.xml", "/catalog.xml", "/catalog.xml", "/catalog.xml", "/catalog.xml")
+	fetcher := &countingFetcher{Fetcher: FSFetcher{FS: selectionTestFS(t)}}
+	merge := profile.Merge{Combine: &profile.Combine{Method: CombineKeep}}
+	p.Merge = &merge
+	c, err := Resolve(context.Background(), p, Options{Fetcher: fetcher, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.max > 2 {
+		t.Errorf("at most 2 imports should be fetched at once, got %d", fetcher.max)
+	}
+	controls := 0
+	for _, g := range selectionTestCatalog().Groups {
+		controls += len(g.Controls)
+	}
+	if len(c.Controls) != 6*controls {
+		t.Errorf("all imports should be resolved, got %d controls", len(c.Controls))
+	}
+}
+
+func TestResolveAggregatesImportErrors(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	p := importsProfile(t, "/missing-a.xml", "/catalog.xml", "/missing-b.xml")
+	_, err := Resolve(context.Background(), p, Options{Fetcher: FSFetcher{FS: selectionTestFS(t)}})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("failures of imports should be aggregated, got %v", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 failed imports, got %v", errs)
+	}
+	for i, href := range []string{"/missing-a.xml", "/missing-b.xml"} {
+		if ierr, ok := errs[i].(*ImportError); !ok || ierr.Href != href {
+			t.Errorf("expected failure of %s, got %v", href, errs[i])
+		}
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("resolution should not leak goroutines, %d before and %d after", goroutines, n)
+	}
+}
+
+func TestResolveWithCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := importsProfile(t, "/catalog.xml")
+	if _, err := Resolve(ctx, p, Options{Fetcher: FSFetcher{FS: selectionTestFS(t)}}); err != context.Canceled {
+		t.Errorf("resolution should stop with the context, got %v", err)
+	}
+	if _, err := mapImports(ctx, p, modifications{}, newImportChain("", Options{}), Options{Fetcher: FSFetcher{FS: selectionTestFS(t)}}); err != context.Canceled {
+		t.Errorf("mapping imports should stop with the context, got %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	href, err := filepath.Abs(fedrampHighProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Explain(context.Background(), readArtifactProfile(t, fedrampHighProfile), "ac-2", Options{Href: href})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Resolved || len(e.Imports) != 2 {
+		t.Fatalf("ac-2 should be resolved through 2 imports, got %+v", e)
+	}
+	nist := e.Imports[0]
+	if !nist.Found || !nist.Selected || len(nist.SelectedBy) == 0 || nist.SelectedBy[0] != `call control-id="ac-2"` {
+		t.Errorf("ac-2 should be selected by its call in the NIST baseline, got %+v", nist)
+	}
+	if filepath.Base(nist.Href) != "NIST_SP-800-53_rev4_HIGH-baseline_profile.xml" || filepath.Base(nist.Source) != "NIST_SP-800-53_rev4_catalog.xml" {
+		t.Errorf("import should name the NIST baseline and its catalog, got %s and %s", nist.Href, nist.Source)
+	}
+	altered := false
+	for _, ae := range e.Alters {
+		if ae.Target == "ac-2" && ae.Source == href && len(ae.Additions) == 1 {
+			altered = true
+		}
+	}
+	if !altered {
+		t.Errorf("alter of ac-2 by the FedRAMP profile should be reported, got %+v", e.Alters)
+	}
+	set := false
+	for _, se := range e.SetParams {
+		if se.ParamID == "ac-2_prm_4" && se.Target == "ac-2" && len(se.Constraints) == 1 {
+			set = true
+		}
+	}
+	if !set {
+		t.Errorf("setting of ac-2_prm_4 should be reported, got %+v", e.SetParams)
+	}
+	var buf bytes.Buffer
+	if err := e.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "ac-2: resolved\n") {
+		t.Errorf("unexpected report %s", buf.String())
+	}
+}
+
+func TestExplainExcludedControl(t *testing.T) {
+	p := importsProfile(t, "/catalog.xml")
+	p.Imports[0].Exclude = &profile.Exclude{
+		IdSelectors:      []profile.Call{profile.Call{ControlId: "ac-2"}},
+		PatternSelectors: []profile.Match{profile.Match{Pattern: "au-.*"}},
+	}
+	e, err := Explain(context.Background(), p, "ac-2.1", Options{Fetcher: FSFetcher{FS: selectionTestFS(t)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Resolved {
+		t.Error("ac-2.1 should not be resolved")
+	}
+	ie := e.Imports[0]
+	if !ie.Found || ie.Selected {
+		t.Error("ac-2.1 should be found but not selected")
+	}
+	if fmt.Sprint(ie.SelectedBy) != `[all with-subcontrols="yes"]` {
+		t.Errorf("ac-2.1 should be selected by all, got %v", ie.SelectedBy)
+	}
+	if fmt.Sprint(ie.ExcludedBy) != `[call control-id="ac-2"]` {
+		t.Errorf("ac-2.1 should be excluded along with ac-2, got %v", ie.ExcludedBy)
+	}
+	if len(ie.Exclusions) != 2 {
+		t.Errorf("all exclusions of the import should be reported, got %v", ie.Exclusions)
+	}
+}
+
+func TestExplainAltersAlongSelectingImport(t *testing.T) {
+	// ac-2 is selected from the catalog directly, not through the profile altering it
+	p := importsProfile(t, "/mid.xml", "/catalog.xml")
+	p.Imports[0].Include = &profile.Include{IdSelectors: []profile.Call{profile.Call{ControlId: "ac-1"}}}
+	p.Imports[1].Include = &profile.Include{IdSelectors: []profile.Call{profile.Call{ControlId: "ac-2"}}}
+	opts := Options{Href: "/root.xml", Fetcher: FSFetcher{FS: conflictTestFS(t)}}
+	e, err := Explain(context.Background(), p, "ac-2", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Resolved || len(e.Alters) != 0 {
+		t.Errorf("ac-2 should be resolved without the alters of another import, got %+v", e.Alters)
+	}
+	e, err = Explain(context.Background(), p, "ac-1", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Alters) != 1 || e.Alters[0].Source != "/mid.xml" || len(e.SetParams) != 2 {
+		t.Errorf("ac-1 should report the modifications of the profile it is imported through, got %+v and %+v", e.Alters, e.SetParams)
+	}
+}
+
+func failTest(err error, t *testing.T) {
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// conflictTestFS serves a catalog with parameters and a profile importing it, which alters ac-1
+// and ac-2 and sets the parameters of ac-1
+func conflictTestFS(t *testing.T) fstest.MapFS {
+	fsys := selectionTestFS(t)
+	c := selectionTestCatalog()
+	c.Groups[0].Controls[0].Params = []catalog.Param{catalog.Param{Id: "ac-1_prm_1"}, catalog.Param{Id: "ac-1_prm_2"}}
+	c.Groups[0].Controls[1].Props = []catalog.Prop{catalog.Prop{Class: "label", Value: "AC-2"}}
+	b, err := xml.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["catalog.xml"] = &fstest.MapFile{Data: b}
+	mid := importsProfile(t, "catalog.xml")
+	mid.Modify = &profile.Modify{
+		ParamSettings: []profile.SetParam{
+			profile.SetParam{Id: "ac-1_prm_1", Value: "mid"},
+			profile.SetParam{Id: "ac-1_prm_2", Value: "mid"},
+		},
+		Alterations: []profile.Alter{
+			profile.Alter{ControlId: "ac-1", Additions: []profile.Add{profile.Add{
+				Position: PositionStarting,
+				Props:    []catalog.Prop{catalog.Prop{Class: "priority", Value: "P2"}},
+				Parts:    []catalog.Part{catalog.Part{Id: "ac-1_gdn", Class: "guidance"}},
+			}}},
+			profile.Alter{
+				ControlId: "ac-2",
+				Removals:  []profile.Remove{profile.Remove{ClassRef: "label"}},
+				Additions: []profile.Add{profile.Add{Props: []catalog.Prop{catalog.Prop{Class: "shared", Value: "yes"}}}},
+			},
+		},
+	}
+	b, err = xml.Marshal(mid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["mid.xml"] = &fstest.MapFile{Data: b}
+	return fsys
+}
+
+// conflictingProfile imports the profile of conflictTestFS. It gives ac-1 another priority and
+// sets ac-1_prm_1 differently, removes the same items from ac-2 and adds the same prop to it.
+func conflictingProfile(t *testing.T) *profile.Profile {
+	p := importsProfile(t, "/mid.xml")
+	p.Modify = &profile.Modify{
+		ParamSettings: []profile.SetParam{profile.SetParam{Id: "ac-1_prm_1", Value: "root"}},
+		Alterations: []profile.Alter{
+			profile.Alter{ControlId: "ac-1", Additions: []profile.Add{profile.Add{Props: []catalog.Prop{catalog.Prop{Class: "priority", Value: "P1"}}}}},
+			profile.Alter{
+				ControlId: "ac-2",
+				Removals:  []profile.Remove{profile.Remove{ClassRef: "label"}},
+				Additions: []profile.Add{profile.Add{Props: []catalog.Prop{catalog.Prop{Class: "shared", Value: "yes"}}}},
+			},
+		},
+	}
+	return p
+}
+
+func TestConflicts(t *testing.T) {
+	opts := Options{Href: "/root.xml", Fetcher: FSFetcher{FS: conflictTestFS(t)}}
+	conflicts, err := Conflicts(context.Background(), conflictingProfile(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the guidance added to ac-1, the prop added to ac-2 by both profiles and the settings of
+	// ac-1_prm_2 by a single profile do not conflict
+	if len(conflicts) != 3 {
+		t.Fatalf("priorities of ac-1, removals from ac-2 and settings of ac-1_prm_1 should conflict, got %v", conflicts)
+	}
+	tt := []struct {
+		kind    string
+		target  string
+		sources []string
+	}{
+		{ConflictAlter, "ac-1", []string{"/root.xml", "/mid.xml"}},
+		{ConflictAlter, "ac-2", []string{"/root.xml", "/mid.xml"}},
+		{ConflictSetParam, "ac-1_prm_1", []string{"/root.xml", "/mid.xml"}},
+	}
+	for i, tc := range tt {
+		c := conflicts[i]
+		var sources []string
+		for _, ca := range c.Alters {
+			sources = append(sources, ca.Source)
+		}
+		for _, cs := range c.SetParams {
+			sources = append(sources, cs.Source)
+		}
+		if c.Kind != tc.kind || c.Target != tc.target || fmt.Sprint(sources) != fmt.Sprint(tc.sources) {
+			t.Errorf("expected %s %s from %v, got %s %s from %v", tc.kind, tc.target, tc.sources, c.Kind, c.Target, sources)
+		}
+	}
+	expected := []string{
+		`alter ac-1 is modified by /root.xml (add position="ending" 1 prop) and /mid.xml (add position="starting" 1 prop)`,
+		`alter ac-2 is modified by /root.xml (remove class-ref="label") and /mid.xml (remove class-ref="label")`,
+		`set-param ac-1_prm_1 is modified by /root.xml (value "root") and /mid.xml (value "mid")`,
+	}
+	for i, c := range conflicts {
+		if c.String() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], c)
+		}
+	}
+}
+
+func TestResolveConflictPolicy(t *testing.T) {
+	fsys := conflictTestFS(t)
+	c, err := Resolve(context.Background(), conflictingProfile(t), Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a merge directive, controls are resolved ungrouped
+	if len(c.Controls) < 2 || c.Controls[0].Id != "ac-1" || c.Controls[1].Id != "ac-2" {
+		t.Fatal("ac-1 and ac-2 should be resolved")
+	}
+	ac1 := c.Controls[0]
+	if len(ac1.Props) != 1 || ac1.Props[0].Value != "P1" {
+		t.Errorf("only the priority of the nearest profile should apply, got %v", ac1.Props)
+	}
+	if len(ac1.Parts) != 1 || ac1.Parts[0].Id != "ac-1_gdn" {
+		t.Errorf("additions of other profiles which do not compete should apply, got %v", ac1.Parts)
+	}
+	if ac2 := c.Controls[1]; len(ac2.Props) != 1 || ac2.Props[0].Class != "shared" {
+		t.Errorf("the label of ac-2 should be removed and the prop added by both profiles added once, got %v", ac2.Props)
+	}
+	values := make(map[string]string)
+	for _, param := range ac1.Params {
+		values[param.Id] = string(param.Value)
+	}
+	// settings of imported profiles apply when the nearer ones leave the parameter alone
+	if values["ac-1_prm_1"] != "root" || values["ac-1_prm_2"] != "mid" {
+		t.Errorf("nearest settings should apply, got %v", values)
+	}
+	setBy := make(map[string]string)
+	alteredBy := make(map[string]bool)
+	for _, link := range ac1.Links {
+		switch link.Rel {
+		case RelSetParam:
+			setBy[link.Value] = link.Href.String()
+		case RelAlter:
+			alteredBy[link.Href.String()] = true
+		}
+	}
+	if setBy["ac-1_prm_1"] != "/root.xml" || setBy["ac-1_prm_2"] != "/mid.xml" {
+		t.Errorf("set-param links should name the setting profiles, got %v", setBy)
+	}
+	if !alteredBy["/root.xml"] || !alteredBy["/mid.xml"] {
+		t.Errorf("alter links should name both profiles altering ac-1, got %v", alteredBy)
+	}
+
+	_, err = Resolve(context.Background(), conflictingProfile(t), Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}, ConflictPolicy: ConflictFail})
+	conflictErr, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("resolution should fail with a ConflictError, got %v", err)
+	}
+	if len(conflictErr.Conflicts) != 3 || !strings.Contains(err.Error(), "/mid.xml") {
+		t.Errorf("all conflicts should be reported with their sources, got %v", err)
+	}
+
+	if _, err := Resolve(context.Background(), conflictingProfile(t), Options{Fetcher: FSFetcher{FS: fsys}, ConflictPolicy: "farthest-wins"}); err == nil {
+		t.Error("unknown conflict policy should fail")
+	}
+}
+
+func TestResolveProvenanceAlongImportChains(t *testing.T) {
+	// ac-2 is selected from the catalog directly, not through the profile altering it
+	p := importsProfile(t, "/mid.xml", "/catalog.xml")
+	p.Imports[0].Include = &profile.Include{IdSelectors: []profile.Call{profile.Call{ControlId: "ac-1"}}}
+	p.Imports[1].Include = &profile.Include{IdSelectors: []profile.Call{profile.Call{ControlId: "ac-2"}}}
+	c, err := Resolve(context.Background(), p, Options{Href: "/root.xml", Fetcher: FSFetcher{FS: conflictTestFS(t)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Controls) != 2 || c.Controls[1].Id != "ac-2" {
+		t.Fatalf("ac-1 and ac-2 should be resolved, got %v", resolvedIDs(c))
+	}
+	for _, link := range c.Controls[1].Links {
+		if link.Rel == RelAlter || link.Rel == RelSetParam {
+			t.Errorf("ac-2 should not link to the modifications of another import, got %v", link)
+		}
+	}
+	alteredBy := false
+	for _, link := range c.Controls[0].Links {
+		alteredBy = alteredBy || link.Rel == RelAlter && link.Href.String() == "/mid.xml"
+	}
+	if !alteredBy {
+		t.Errorf("ac-1 should link to the profile altering it, got %v", c.Controls[0].Links)
+	}
+}
+
+func TestConflictsRankedByImportDepth(t *testing.T) {
+	fsys := selectionTestFS(t)
+	priority := func(value string) string {
+		return fmt.Sprintf(`<modify><alter control-id="ac-1"><add><prop class="priority">%s</prop></add></alter></modify>`, value)
+	}
+	// a imports deep, which gives ac-1 a priority as b does, deep being one more import away
+	fsys["deep.xml"] = &fstest.MapFile{Data: []byte(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<import href="catalog.xml"><include><call control-id="ac-1"/></include></import>` + priority("deep") + `</profile>`)}
+	fsys["a.xml"] = &fstest.MapFile{Data: []byte(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0"><import href="deep.xml"/></profile>`)}
+	fsys["b.xml"] = &fstest.MapFile{Data: []byte(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<import href="catalog.xml"><include><call control-id="ac-1"/></include></import>` + priority("b") + `</profile>`)}
+	p := importsProfile(t, "/a.xml", "/b.xml")
+	p.Merge = &profile.Merge{Combine: &profile.Combine{Method: CombineMerge}}
+	opts := Options{Href: "/root.xml", Fetcher: FSFetcher{FS: fsys}}
+
+	conflicts, err := Conflicts(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || len(conflicts[0].Alters) != 2 || conflicts[0].Alters[0].Source != "/b.xml" || conflicts[0].Alters[1].Source != "/deep.xml" {
+		t.Fatalf("the priority of b, fewer imports away, should win over the one of deep, got %v", conflicts)
+	}
+	resolved, err := Resolve(context.Background(), p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props := resolved.Controls[0].Props; len(props) != 1 || props[0].Value != "b" {
+		t.Errorf("only the priority of b should apply, got %v", props)
+	}
+}
+
+func TestResolveLeavesProfileUnchanged(t *testing.T) {
+	fsys := fstest.MapFS{
+		"catalog.xml": &fstest.MapFile{Data: []byte(`<catalog xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+			<control id="ac-1"><title>Policy</title><param id="ac-1_prm_1"><label>frequency</label></param></control>
+		</catalog>`)},
+	}
+	p, err := ReadProfile(strings.NewReader(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0">
+		<import href="/catalog.xml"/>
+		<modify>
+			<set-param param-id="ac-1_prm_1"><constraint>annually</constraint></set-param>
+			<alter control-id="ac-1"><add><part id="ac-1_smt.z"><p>Review <insert param-id="ac-1_prm_1"/></p></part></add></alter>
+		</modify>
+	</profile>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := p.DeepCopy()
+	for i := 0; i < 2; i++ {
+		resolved, err := Resolve(context.Background(), p, Options{Href: "/profile.xml", Fetcher: FSFetcher{FS: fsys}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		InsertParamValues(resolved)
+		if text := resolved.Controls[0].Parts[0].Prose.Text(); text != "Review annually" {
+			t.Errorf("expected the added part to be rendered with the parameter value, got %q", text)
+		}
+		if !reflect.DeepEqual(p, original) {
+			t.Fatal("resolving a profile should not change it")
+		}
 	}
 }
@@ -1,106 +1,364 @@
 package generator
 
 import (
-	"fmt"
-
-	"github.com/docker/oscalkit/impl"
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/sirupsen/logrus"
+)
+
+// Positions of added content with respect to the targeted control or subcontrol. Content added
+// before or after is a sibling of the control or subcontrol, which a catalog cannot hold: such
+// additions are skipped with a warning.
+const (
+	PositionBefore   = "before"
+	PositionAfter    = "after"
+	PositionStarting = "starting"
+	PositionEnding   = "ending"
 )
 
 // ProcessAddition processes additions of a profile
 func ProcessAddition(alt profile.Alter, controls []catalog.Control) []catalog.Control {
-	for j, ctrl := range controls {
-		if ctrl.Id == alt.ControlId {
-			for _, add := range alt.Additions {
-				for _, p := range add.Parts {
-					appended := false
-					for _, catalogPart := range ctrl.Parts {
-						if p.Class == catalogPart.Class {
-							appended = true
-							// append with all the parts with matching classes
-							parts := ModifyParts(p, ctrl.Parts)
-							ctrl.Parts = parts
-						}
-					}
-					if !appended {
-						ctrl.Parts = append(ctrl.Parts, p)
-					}
-				}
+	for j := range controls {
+		if controls[j].Id == alt.ControlId {
+			addToControl(alt, &controls[j])
+		}
+		for k := range controls[j].Subcontrols {
+			if controls[j].Subcontrols[k].Id == alt.SubcontrolId {
+				addToSubcontrol(alt, &controls[j].Subcontrols[k])
 			}
-			controls[j] = ctrl
-		}
-		for k, subctrl := range controls[j].Subcontrols {
-			if subctrl.Id == alt.SubcontrolId {
-				for _, add := range alt.Additions {
-					for _, p := range add.Parts {
-						appended := false
-						for _, catalogPart := range subctrl.Parts {
-							if p.Class == catalogPart.Class {
-								appended = true
-								// append with all the parts
-								parts := ModifyParts(p, subctrl.Parts)
-								subctrl.Parts = parts
-							}
-						}
-						if !appended {
-							subctrl.Parts = append(subctrl.Parts, p)
-						}
-					}
-
-				}
+		}
+	}
+	return controls
+}
+
+func addToControl(alt profile.Alter, ctrl *catalog.Control) {
+	for _, add := range alt.Additions {
+		position := additionPosition(add)
+		if !insidePosition(position) {
+			logrus.Warnf("cannot add content %s control %s, skipping the addition", position, ctrl.Id)
+			continue
+		}
+		if add.Title != "" {
+			ctrl.Title = add.Title
+		}
+		ctrl.Props = insertProps(ctrl.Props, add.Props, position)
+		ctrl.Links = insertLinks(ctrl.Links, add.Links, position)
+		ctrl.Params = insertParams(ctrl.Params, add.Params, position)
+		ctrl.References = insertReferences(ctrl.References, add.References, position)
+		ctrl.Parts = insertParts(ctrl.Parts, add.Parts, position)
+	}
+}
+
+func addToSubcontrol(alt profile.Alter, subctrl *catalog.Subcontrol) {
+	for _, add := range alt.Additions {
+		position := additionPosition(add)
+		if !insidePosition(position) {
+			logrus.Warnf("cannot add content %s subcontrol %s, skipping the addition", position, subctrl.Id)
+			continue
+		}
+		if add.Title != "" {
+			subctrl.Title = add.Title
+		}
+		subctrl.Props = insertProps(subctrl.Props, add.Props, position)
+		subctrl.Links = insertLinks(subctrl.Links, add.Links, position)
+		subctrl.Params = insertParams(subctrl.Params, add.Params, position)
+		subctrl.References = insertReferences(subctrl.References, add.References, position)
+		subctrl.Parts = insertParts(subctrl.Parts, add.Parts, position)
+	}
+}
+
+func additionPosition(add profile.Add) string {
+	switch add.Position {
+	case PositionBefore, PositionAfter, PositionStarting, PositionEnding:
+		return add.Position
+	case "":
+		return PositionEnding
+	}
+	logrus.Warnf("unknown position %s for addition, adding at the end", add.Position)
+	return PositionEnding
+}
+
+// insidePosition tells whether content added at the position goes inside the targeted control
+// or subcontrol
+func insidePosition(position string) bool {
+	return position == PositionStarting || position == PositionEnding
+}
+
+// atStart tells if added items go in front of the existing ones
+func atStart(position string) bool {
+	return position == PositionStarting
+}
+
+func insertProps(props, added []catalog.Prop, position string) []catalog.Prop {
+	if len(added) == 0 {
+		return props
+	}
+	if atStart(position) {
+		return append(append([]catalog.Prop{}, added...), props...)
+	}
+	return append(append([]catalog.Prop{}, props...), added...)
+}
+
+func insertLinks(links, added []catalog.Link, position string) []catalog.Link {
+	if len(added) == 0 {
+		return links
+	}
+	if atStart(position) {
+		return append(append([]catalog.Link{}, added...), links...)
+	}
+	return append(append([]catalog.Link{}, links...), added...)
+}
+
+func insertParams(params, added []catalog.Param, position string) []catalog.Param {
+	if len(added) == 0 {
+		return params
+	}
+	if atStart(position) {
+		return append(append([]catalog.Param{}, added...), params...)
+	}
+	return append(append([]catalog.Param{}, params...), added...)
+}
+
+func insertReferences(refs, added *catalog.References, position string) *catalog.References {
+	if added == nil {
+		return refs
+	}
+	if refs == nil {
+		return added
+	}
+	newRefs := *refs
+	newRefs.Links = insertLinks(refs.Links, added.Links, position)
+	if atStart(position) {
+		newRefs.Refs = append(append([]catalog.Ref{}, added.Refs...), refs.Refs...)
+	} else {
+		newRefs.Refs = append(append([]catalog.Ref{}, refs.Refs...), added.Refs...)
+	}
+	return &newRefs
+}
+
+func insertParts(parts, added []catalog.Part, position string) []catalog.Part {
+	if len(added) == 0 {
+		return parts
+	}
+	if atStart(position) {
+		return append(append([]catalog.Part{}, added...), parts...)
+	}
+	return append(append([]catalog.Part{}, parts...), added...)
+}
+
+// ProcessRemoval processes removals of a profile
+func ProcessRemoval(alt profile.Alter, controls []catalog.Control) []catalog.Control {
+	for j := range controls {
+		if controls[j].Id == alt.ControlId {
+			removeFromControl(alt, &controls[j])
+		}
+		for k := range controls[j].Subcontrols {
+			if controls[j].Subcontrols[k].Id == alt.SubcontrolId {
+				removeFromSubcontrol(alt, &controls[j].Subcontrols[k])
 			}
-			controls[j].Subcontrols[k] = subctrl
 		}
 	}
 	return controls
 }
 
-// ProcessAlterations processes alteration section of a profile
+func removeFromControl(alt profile.Alter, ctrl *catalog.Control) {
+	for _, rm := range alt.Removals {
+		if removes(rm, "title", "", "") {
+			ctrl.Title = ""
+		}
+		if ctrl.References != nil && removes(rm, "references", ctrl.References.Id, "") {
+			ctrl.References = nil
+		}
+		ctrl.Props = removeProps(rm, ctrl.Props)
+		ctrl.Links = removeLinks(rm, ctrl.Links)
+		ctrl.Params = removeParams(rm, ctrl.Params)
+		ctrl.Parts = removeParts(rm, ctrl.Parts)
+	}
+}
+
+func removeFromSubcontrol(alt profile.Alter, subctrl *catalog.Subcontrol) {
+	for _, rm := range alt.Removals {
+		if removes(rm, "title", "", "") {
+			subctrl.Title = ""
+		}
+		if subctrl.References != nil && removes(rm, "references", subctrl.References.Id, "") {
+			subctrl.References = nil
+		}
+		subctrl.Props = removeProps(rm, subctrl.Props)
+		subctrl.Links = removeLinks(rm, subctrl.Links)
+		subctrl.Params = removeParams(rm, subctrl.Params)
+		subctrl.Parts = removeParts(rm, subctrl.Parts)
+	}
+}
+
+// ProcessAlterations processes alteration section of a profile. Removals of an alter are
+// processed before its additions. The catalog is changed in place, see Catalog.DeepCopy to keep it.
 func ProcessAlterations(alterations []profile.Alter, c *catalog.Catalog) *catalog.Catalog {
 	for _, alt := range alterations {
-		for i, g := range c.Groups {
-			c.Groups[i].Controls = ProcessAddition(alt, g.Controls)
-		}
+		catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, _ []catalog.Node) error {
+			switch {
+			case n.Control != nil && n.Control.Id == alt.ControlId:
+				removeFromControl(alt, n.Control)
+				addToControl(alt, n.Control)
+			case n.Subcontrol != nil && n.Subcontrol.Id == alt.SubcontrolId:
+				removeFromSubcontrol(alt, n.Subcontrol)
+				addToSubcontrol(alt, n.Subcontrol)
+			case n.Part != nil:
+				// alters target controls and subcontrols only
+				return catalog.SkipChildren
+			}
+			return nil
+		}))
 	}
 	return c
 }
 
-// ProcessSetParam processes set-param of a profile
-func ProcessSetParam(setParams []profile.SetParam, c *catalog.Catalog, catalogHelper impl.Catalog) *catalog.Catalog {
+// ProcessSetParam processes set-param of a profile. The values set replace the ones of the
+// targeted parameter wherever it is in the catalog, links are added to the existing ones.
+// Insert markers in prose are left as they are, see InsertParamValues.
+func ProcessSetParam(setParams []profile.SetParam, c *catalog.Catalog) *catalog.Catalog {
 	for _, sp := range setParams {
-		ctrlID := catalogHelper.GetControl(sp.Id)
-		for i, g := range c.Groups {
-			for j, catalogCtrl := range g.Controls {
-				if ctrlID == catalogCtrl.Id {
-					for k := range catalogCtrl.Parts {
-						if len(sp.Constraints) == 0 {
-							continue
-						}
-						c.Groups[i].Controls[j].Parts[k].ModifyProse(sp.Id, sp.Constraints[0].Value)
-					}
-				}
+		catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, _ []catalog.Node) error {
+			switch {
+			case n.Param != nil && n.Param.Id == sp.Id:
+				setParam(sp, n.Param)
+			case n.Part != nil:
+				return catalog.SkipChildren
 			}
+			return nil
+		}))
+	}
+	return c
+}
+
+func setParam(sp profile.SetParam, param *catalog.Param) {
+	if sp.Class != "" {
+		param.Class = sp.Class
+	}
+	if sp.DependsOn != "" {
+		param.DependsOn = sp.DependsOn
+	}
+	if sp.Label != "" {
+		param.Label = sp.Label
+	}
+	if sp.Value != "" {
+		param.Value = sp.Value
+	}
+	if sp.Select != nil {
+		param.Select = sp.Select
+	}
+	if len(sp.Descriptions) > 0 {
+		param.Descriptions = sp.Descriptions
+	}
+	if len(sp.Constraints) > 0 {
+		param.Constraints = sp.Constraints
+	}
+	// parts of a set-param carry guidance for the parameter
+	if len(sp.Parts) > 0 {
+		param.Guidance = nil
+		for _, part := range sp.Parts {
+			param.Guidance = append(param.Guidance, catalog.Guideline{Prose: part.Prose})
 		}
 	}
+	param.Links = mergeLinks(param.Links, sp.Links)
+}
+
+// InsertParamValues renders the insert markers in the prose of a catalog with the values of
+// the parameters they refer to. A parameter without a value is rendered with its first
+// constraint, parameters with neither are left as insert markers.
+func InsertParamValues(c *catalog.Catalog) *catalog.Catalog {
+	catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, ancestors []catalog.Node) error {
+		if n.Part == nil || n.Part.Prose == nil {
+			return nil
+		}
+		// parameters of the enclosing groups, control and subcontrol apply, outermost first
+		for _, a := range ancestors {
+			switch {
+			case a.Group != nil:
+				insertValues(n.Part.Prose, a.Group.Params)
+			case a.Control != nil:
+				insertValues(n.Part.Prose, a.Control.Params)
+			case a.Subcontrol != nil:
+				insertValues(n.Part.Prose, a.Subcontrol.Params)
+			}
+		}
+		return nil
+	}))
 	return c
 }
 
-// ModifyParts modifies parts
-func ModifyParts(p catalog.Part, controlParts []catalog.Part) []catalog.Part {
+func insertValues(prose *catalog.Prose, params []catalog.Param) {
+	for _, param := range params {
+		value := string(param.Value)
+		if value == "" && len(param.Constraints) > 0 {
+			value = param.Constraints[0].Value
+		}
+		if value == "" {
+			continue
+		}
+		prose.ReplaceInsertParams(param.Id, value)
+	}
+}
+
+// removes checks whether a remove targets an item of the given name, id and class.
+// All of item-name, id-ref and class-ref given by the remove have to match.
+func removes(rm profile.Remove, itemName, id, class string) bool {
+	if rm.ItemName == "" && rm.IdRef == "" && rm.ClassRef == "" {
+		return false
+	}
+	if rm.ItemName != "" && rm.ItemName != itemName {
+		return false
+	}
+	if rm.IdRef != "" && rm.IdRef != id {
+		return false
+	}
+	if rm.ClassRef != "" && rm.ClassRef != class {
+		return false
+	}
+	return true
+}
+
+func removeProps(rm profile.Remove, props []catalog.Prop) []catalog.Prop {
+	var kept []catalog.Prop
+	for _, prop := range props {
+		if !removes(rm, "prop", prop.Id, prop.Class) {
+			kept = append(kept, prop)
+		}
+	}
+	return kept
+}
+
+func removeLinks(rm profile.Remove, links []catalog.Link) []catalog.Link {
+	var kept []catalog.Link
+	for _, link := range links {
+		if !removes(rm, "link", "", "") {
+			kept = append(kept, link)
+		}
+	}
+	return kept
+}
+
+func removeParams(rm profile.Remove, params []catalog.Param) []catalog.Param {
+	var kept []catalog.Param
+	for _, param := range params {
+		if !removes(rm, "param", param.Id, param.Class) {
+			kept = append(kept, param)
+		}
+	}
+	return kept
+}
 
-	// append with all the parts
-	var parts []catalog.Part
-	for i, part := range controlParts {
-		if p.Class != part.Class {
-			parts = append(parts, part)
+// removeParts removes matching parts, along with matching props, links and parts nested in the remaining ones
+func removeParts(rm profile.Remove, parts []catalog.Part) []catalog.Part {
+	var kept []catalog.Part
+	for _, part := range parts {
+		if removes(rm, "part", part.Id, part.Class) {
 			continue
 		}
-		id := part.Id
-		part.Id = fmt.Sprintf("%s_%d", id, i)
-		parts = append(parts, part)
-		part.Id = fmt.Sprintf("%s_%d", id, i+1)
-		parts = append(parts, part)
+		part.Props = removeProps(rm, part.Props)
+		part.Links = removeLinks(rm, part.Links)
+		part.Parts = removeParts(rm, part.Parts)
+		kept = append(kept, part)
 	}
-	return parts
+	return kept
 }
@@ -3,206 +3,267 @@ package generator
 import (
 	"context"
 	"fmt"
-	"os"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/docker/oscalkit/impl"
-	"github.com/docker/oscalkit/types/oscal"
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
 	"github.com/sirupsen/logrus"
 )
 
+// mappedImport is the catalog mapped for a profile import along with the href of the catalog it was taken from
+type mappedImport struct {
+	profileImport profile.Import
+	source        string
+	catalog       *catalog.Catalog
+	// index and selection tell which controls the source catalog has and how they were selected
+	index     catalogIndex
+	selection selection
+	// modifiedBy are the profiles along the import chains of the import whose modifications
+	// were applied to its catalog
+	modifiedBy []string
+}
+
+// sourcedCatalog is a catalog found up the import chain along with its href
+type sourcedCatalog struct {
+	href    string
+	catalog *catalog.Catalog
+	// modifiedBy are the profiles whose modifications were applied to an imported profile's catalog
+	modifiedBy []string
+}
+
 // CreateCatalogsFromProfile maps profile controls to multiple catalogs
 func CreateCatalogsFromProfile(profileArg *profile.Profile) ([]*catalog.Catalog, error) {
+	return ResolveImports(context.Background(), profileArg, Options{})
+}
 
-	t := time.Now()
-	done := 0
-	errChan := make(chan error)
-	catalogChan := make(chan *catalog.Catalog)
-	var outputCatalogs []*catalog.Catalog
-	logrus.Info("fetching alterations...")
-	alterations, err := GetAlters(profileArg)
-	if err != nil {
-		return nil, err
-	}
-	logrus.Info("fetching alterations from import chain complete")
+// ResolveImports resolves each import of a profile into a catalog of its own, in the order of
+// the profile, applying the modifications of the import chain without merging the catalogs.
+func ResolveImports(ctx context.Context, profileArg *profile.Profile, opts Options) ([]*catalog.Catalog, error) {
+	return memoize(ctx, "imports", profileArg, opts, func() ([]*catalog.Catalog, error) {
+		imports, _, _, err := mapProfile(ctx, profileArg, opts)
+		if err != nil {
+			return nil, err
+		}
+		outputCatalogs := make([]*catalog.Catalog, 0, len(imports))
+		for _, mi := range imports {
+			outputCatalogs = append(outputCatalogs, mi.catalog)
+		}
+		return outputCatalogs, nil
+	})
+}
 
-	logrus.Debug("processing alteration and parameters... \nmapping to controls...")
-	// Get first import of the profile (which is a catalog)
+// mapImports fetches the catalog of each profile import, applies the modifications of the import
+// chain and maps the controls selected by the import. Imports are mapped by a bounded number
+// of workers and returned in the order of the profile, whichever finishes first. The failures
+// of all imports are reported together.
+func mapImports(ctx context.Context, profileArg *profile.Profile, m modifications, chain importChain, opts Options) ([]mappedImport, error) {
+
+	t := time.Now()
 	for _, profileImport := range profileArg.Imports {
 		err := ValidateHref(profileImport.Href)
 		if err != nil {
 			return nil, err
 		}
-		go func(profileImport profile.Import) {
-			catalogHelper := impl.NISTCatalog{}
-			c := make(chan *catalog.Catalog)
-			e := make(chan error)
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-			// ForEach Import's Href, Fetch the Catalog JSON file
-			getCatalogForImport(ctx, profileImport, c, e, profileImport.Href.String())
-			select {
-			case importedCatalog := <-c:
-				// Prepare a new catalog object to merge into the final List of OutputCatalogs
-				if profileArg.Modify != nil {
-					nc := impl.NISTCatalog{}
-					importedCatalog = ProcessAlterations(alterations, importedCatalog)
-					importedCatalog = ProcessSetParam(profileArg.Modify.ParamSettings, importedCatalog, &nc)
-				}
-				newCatalog, err := GetMappedCatalogControlsFromImport(importedCatalog, profileImport, &catalogHelper)
-				if err != nil {
-					errChan <- err
-					return
-				}
-				catalogChan <- &newCatalog
-
-			case err := <-e:
-				errChan <- err
-				return
-			}
-
-		}(profileImport)
+	}
 
+	logrus.Debug("processing alteration and parameters... \nmapping to controls...")
+	docs := opts.documents()
+	mapped := make([]mappedImport, len(profileArg.Imports))
+	errs := make([]error, len(profileArg.Imports))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.concurrency() && w < len(profileArg.Imports); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mapped[i], errs[i] = mapImport(ctx, profileArg.Imports[i], m, chain, docs)
+			}
+		}()
 	}
-	for {
+feed:
+	for i := range profileArg.Imports {
 		select {
-		case err := <-errChan:
-			return nil, err
-		case newCatalog := <-catalogChan:
-			done++
-			if newCatalog != nil {
-				outputCatalogs = append(outputCatalogs, newCatalog)
-			}
-			if done == len(profileArg.Imports) {
-				logrus.Infof("successfully mapped controls in %f seconds", time.Since(t).Seconds())
-				return outputCatalogs, nil
-			}
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
 	}
-}
-func getSubControl(call profile.Call, ctrls []catalog.Control, helper impl.Catalog) (catalog.Subcontrol, error) {
-	for _, ctrl := range ctrls {
-		if ctrl.Id == helper.GetControl(call.SubcontrolId) {
-			for _, subctrl := range ctrl.Subcontrols {
-				if subctrl.Id == call.SubcontrolId {
-					return subctrl, nil
-				}
-			}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var failed Errors
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &ImportError{Href: profileArg.Imports[i].Href.String(), Err: err})
 		}
 	}
-	return catalog.Subcontrol{}, fmt.Errorf("could not find subcontrol %s in catalog", call.SubcontrolId)
+	if len(failed) > 0 {
+		return nil, failed
+	}
+	logrus.Infof("successfully mapped controls in %f seconds", time.Since(t).Seconds())
+	return mapped, nil
+}
+
+// mapImport maps the controls selected by a single import of a profile, once the modifications
+// declared by the importing profile, at the end of chain, are applied to the imported catalog
+func mapImport(ctx context.Context, profileImport profile.Import, m modifications, chain importChain, docs documents) (mappedImport, error) {
+	if err := ctx.Err(); err != nil {
+		return mappedImport{}, err
+	}
+	imported, err := getCatalogForImport(ctx, profileImport, m, chain, docs)
+	if err != nil {
+		return mappedImport{}, err
+	}
+	// alterations apply to a copy, leaving the imported catalog as it was read
+	declared := m.declaredBy(chain.href())
+	importedCatalog := ProcessAlterations(alters(declared.alters), imported.catalog.DeepCopy())
+	importedCatalog = ProcessSetParam(setParams(declared.setParams), importedCatalog)
+	index := newCatalogIndex(importedCatalog)
+	s, err := selectFromIndex(index, profileImport)
+	if err != nil {
+		return mappedImport{}, err
+	}
+	newCatalog := mapSelection(importedCatalog, s)
+	return mappedImport{
+		profileImport: profileImport,
+		source:        imported.href,
+		catalog:       &newCatalog,
+		index:         index,
+		selection:     s,
+		modifiedBy:    append(append([]string{}, imported.modifiedBy...), chain.href()),
+	}, nil
 }
 
-// GetMappedCatalogControlsFromImport gets mapped controls in catalog per profile import
-func GetMappedCatalogControlsFromImport(importedCatalog *catalog.Catalog, profileImport profile.Import, catalogHelper impl.Catalog) (catalog.Catalog, error) {
+// CreateMergedCatalogFromProfile resolves a profile into a single catalog, merging the
+// catalogs of its imports as per the profile's merge directive
+func CreateMergedCatalogFromProfile(profileArg *profile.Profile) (*catalog.Catalog, error) {
+	catalogs, err := CreateCatalogsFromProfile(profileArg)
+	if err != nil {
+		return nil, err
+	}
+	return MergeCatalogs(profileArg.Merge, catalogs)
+}
+
+// GetMappedCatalogControlsFromImport gets mapped controls in catalog per profile import.
+// Controls are mapped wherever they are in the group hierarchy of the catalog, and the groups
+// holding mapped controls are kept along with their metadata.
+func GetMappedCatalogControlsFromImport(importedCatalog *catalog.Catalog, profileImport profile.Import) (catalog.Catalog, error) {
+	s, err := selectControls(importedCatalog, profileImport)
+	if err != nil {
+		return catalog.Catalog{}, err
+	}
+	return mapSelection(importedCatalog, s), nil
+}
+
+// mapSelection maps the selected controls of a catalog
+func mapSelection(importedCatalog *catalog.Catalog, s selection) catalog.Catalog {
 	newCatalog := catalog.Catalog{
 		Title:  importedCatalog.Title,
 		Groups: []catalog.Group{},
 	}
-
+	newCatalog.Controls = mapControls(importedCatalog.Controls, s)
 	for _, group := range importedCatalog.Groups {
-		newGroup := catalog.Group{
-			Title:    group.Title,
-			Controls: []catalog.Control{},
-		}
-		for _, ctrl := range group.Controls {
-			for _, call := range profileImport.Include.IdSelectors {
-				if call.ControlId == "" {
-					if strings.ToLower(ctrl.Id) == strings.ToLower(catalogHelper.GetControl(call.SubcontrolId)) {
-						ctrlExistsInGroup := false
-						sc, err := getSubControl(call, group.Controls, &impl.NISTCatalog{})
-						if err != nil {
-							return catalog.Catalog{}, err
-						}
-						for i, mappedCtrl := range newGroup.Controls {
-							if mappedCtrl.Id == strings.ToLower(catalogHelper.GetControl(call.SubcontrolId)) {
-								ctrlExistsInGroup = true
-								newGroup.Controls[i].Subcontrols = append(newGroup.Controls[i].Subcontrols, sc)
-							}
-						}
-						if !ctrlExistsInGroup {
-							newGroup.Controls = append(newGroup.Controls,
-								catalog.Control{
-									Id:          ctrl.Id,
-									Class:       ctrl.Class,
-									Title:       ctrl.Title,
-									Params:      ctrl.Params,
-									Parts:       ctrl.Parts,
-									Subcontrols: []catalog.Subcontrol{sc},
-								})
-						}
-					}
-				}
-				if strings.ToLower(call.ControlId) == strings.ToLower(ctrl.Id) {
-					ctrlExists := false
-					for _, x := range newGroup.Controls {
-						if x.Id == ctrl.Id {
-							ctrlExists = true
-							continue
-						}
-					}
-					if !ctrlExists {
-						newGroup.Controls = append(newGroup.Controls,
-							catalog.Control{
-								Id:          ctrl.Id,
-								Class:       ctrl.Class,
-								Title:       ctrl.Title,
-								Subcontrols: []catalog.Subcontrol{},
-								Params:      ctrl.Params,
-								Parts:       ctrl.Parts,
-							},
-						)
-					}
-				}
-			}
-		}
-		if len(newGroup.Controls) > 0 {
+		if newGroup, ok := mapGroup(group, s); ok {
 			newCatalog.Groups = append(newCatalog.Groups, newGroup)
 		}
 	}
-	return newCatalog, nil
+	return newCatalog
 }
 
-func getCatalogForImport(ctx context.Context, i profile.Import, c chan *catalog.Catalog, e chan error, basePath string) {
-	go func(i profile.Import) {
-		err := ValidateHref(i.Href)
-		if err != nil {
-			e <- fmt.Errorf("href cannot be nil")
-			return
-		}
-		path, err := GetFilePath(i.Href.String())
-		if err != nil {
-			e <- err
-			return
+// mapGroup maps the selected controls of a group and its subgroups, telling whether any was selected
+func mapGroup(group catalog.Group, s selection) (catalog.Group, bool) {
+	newGroup := group
+	newGroup.Controls = mapControls(group.Controls, s)
+	newGroup.Groups = nil
+	for _, g := range group.Groups {
+		if subgroup, ok := mapGroup(g, s); ok {
+			newGroup.Groups = append(newGroup.Groups, subgroup)
 		}
-		f, err := os.Open(path)
-		if err != nil {
-			e <- err
-			return
+	}
+	return newGroup, len(newGroup.Controls) > 0 || len(newGroup.Groups) > 0
+}
+
+func mapControls(controls []catalog.Control, s selection) []catalog.Control {
+	mapped := []catalog.Control{}
+	for _, ctrl := range controls {
+		if !s.hasControl(ctrl.Id) {
+			continue
 		}
-		defer f.Close()
-		o, err := oscal.New(f)
-		if err != nil {
-			e <- err
-			return
+		subcontrols := []catalog.Subcontrol{}
+		for _, sc := range ctrl.Subcontrols {
+			if s.hasSubcontrol(sc.Id) {
+				subcontrols = append(subcontrols, sc)
+			}
 		}
-		if o.Catalog != nil {
-			c <- o.Catalog
-			return
+		// keep everything the alterations added to the control such as props and links
+		ctrl.Subcontrols = subcontrols
+		mapped = append(mapped, ctrl)
+	}
+	return mapped
+}
+
+// getCatalogForImport finds the catalog behind an import. An imported profile is resolved into a
+// catalog of its own: each of its imports is mapped as per its selection and the modifications
+// it declares, and the mapped catalogs are merged as per its merge directive.
+func getCatalogForImport(ctx context.Context, i profile.Import, m modifications, chain importChain, docs documents) (sourcedCatalog, error) {
+	err := ValidateHref(i.Href)
+	if err != nil {
+		return sourcedCatalog{}, fmt.Errorf("href cannot be nil")
+	}
+	next, err := chain.follow(i.Href.String())
+	if err != nil {
+		return sourcedCatalog{}, err
+	}
+	o, _, err := docs.read(ctx, i.Href.String())
+	if err != nil {
+		return sourcedCatalog{}, err
+	}
+	if o.Catalog != nil {
+		return sourcedCatalog{href: i.Href.String(), catalog: o.Catalog}, nil
+	}
+	// imports of the imported profile are relative to the profile itself
+	importedProfile, err := SetBasePath(o.Profile.DeepCopy(), i.Href.String())
+	if err != nil {
+		return sourcedCatalog{}, err
+	}
+	if len(importedProfile.Imports) == 0 {
+		return sourcedCatalog{}, fmt.Errorf("profile %s does not import any catalog", i.Href.String())
+	}
+	catalogs := make([]*catalog.Catalog, 0, len(importedProfile.Imports))
+	sources := make(map[string]bool)
+	var modifiedBy []string
+	var failed Errors
+	for _, imp := range importedProfile.Imports {
+		mi, err := mapImport(ctx, imp, m, next, docs)
+		if err != nil && ctx.Err() != nil {
+			return sourcedCatalog{}, ctx.Err()
 		}
-		newP, err := SetBasePath(o.Profile, basePath)
 		if err != nil {
-			e <- err
-			return
+			failed = append(failed, &ImportError{Href: imp.Href.String(), Err: err})
+			continue
 		}
-		o.Profile = newP
-		for _, p := range o.Profile.Imports {
-			go func(p profile.Import) {
-				getCatalogForImport(ctx, p, c, e, basePath)
-			}(p)
+		catalogs = append(catalogs, mi.catalog)
+		sources[mi.source] = true
+		modifiedBy = append(modifiedBy, mi.modifiedBy...)
+	}
+	if len(failed) > 0 {
+		return sourcedCatalog{}, failed
+	}
+	merged, err := MergeCatalogs(importedProfile.Merge, catalogs)
+	if err != nil {
+		return sourcedCatalog{}, err
+	}
+	// controls taken from several catalogs are sourced from the profile bringing them together
+	href := i.Href.String()
+	if len(sources) == 1 {
+		for source := range sources {
+			href = source
 		}
-	}(i)
+	}
+	return sourcedCatalog{href: href, catalog: merged, modifiedBy: modifiedBy}, nil
 }
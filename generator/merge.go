@@ -0,0 +1,415 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+const (
+	// CombineUseFirst keeps the first instance of a control which arrives from several imports
+	CombineUseFirst = "use-first"
+	// CombineMerge merges the contents of all instances of a control into one
+	CombineMerge = "merge"
+	// CombineKeep keeps every instance of a control
+	CombineKeep = "keep"
+)
+
+// Orders of the controls matched in a custom merge. Matched controls keep their order
+// of selection unless sorted by id.
+const (
+	OrderKeep       = "keep"
+	OrderAscending  = "ascending"
+	OrderDescending = "descending"
+)
+
+// pooledControl is a control collected from the catalogs of a profile's imports along with the
+// groups it came from, outermost first
+type pooledControl struct {
+	groups  []catalog.Group
+	control catalog.Control
+}
+
+// controlPool collects controls from catalogs, handling controls arriving more than once as per the combine method
+type controlPool struct {
+	method   string
+	controls []pooledControl
+	index    map[string]int
+}
+
+// MergeCatalogs merges the catalogs mapped per profile import into a single catalog.
+// Competing instances of the same control are combined with the method of the merge
+// directive (use-first when none is given). Controls are structured as in their source
+// catalogs for as-is, grouped as framed by a custom merge, or listed without groups otherwise.
+func MergeCatalogs(merge *profile.Merge, catalogs []*catalog.Catalog) (*catalog.Catalog, error) {
+	if merge == nil {
+		merge = &profile.Merge{}
+	}
+	method := CombineUseFirst
+	if merge.Combine != nil && merge.Combine.Method != "" {
+		method = merge.Combine.Method
+	}
+	pool, err := combineControls(catalogs, method)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &catalog.Catalog{}
+	if len(catalogs) > 0 {
+		merged.Title = catalogs[0].Title
+	}
+	switch {
+	case merge.Custom != nil:
+		merged.Controls, err = customControls(pool, merge.Custom.IdSelectors, merge.Custom.PatternSelectors)
+		if err != nil {
+			return nil, err
+		}
+		merged.Groups, err = customGroups(pool, merge.Custom.Groups)
+		if err != nil {
+			return nil, err
+		}
+	case merge.AsIs != "":
+		merged.Groups, merged.Controls = asIsGroups(pool)
+	default:
+		for _, pc := range pool {
+			merged.Controls = append(merged.Controls, pc.control)
+		}
+	}
+	return merged, nil
+}
+
+// combineControls pools the controls of all catalogs in order, handling controls arriving more than once as per the combine method
+func combineControls(catalogs []*catalog.Catalog, method string) ([]pooledControl, error) {
+	pool := &controlPool{method: method, index: make(map[string]int)}
+	for _, c := range catalogs {
+		if err := pool.add(nil, c.Controls); err != nil {
+			return nil, err
+		}
+		for _, g := range c.Groups {
+			if err := pool.addGroup(nil, g); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pool.controls, nil
+}
+
+func (pool *controlPool) addGroup(groups []catalog.Group, g catalog.Group) error {
+	groups = append(append([]catalog.Group{}, groups...), g)
+	if err := pool.add(groups, g.Controls); err != nil {
+		return err
+	}
+	for _, subgroup := range g.Groups {
+		if err := pool.addGroup(groups, subgroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pool *controlPool) add(groups []catalog.Group, controls []catalog.Control) error {
+	for _, ctrl := range controls {
+		id := strings.ToLower(ctrl.Id)
+		i, exists := pool.index[id]
+		switch {
+		case !exists || pool.method == CombineKeep:
+			pool.index[id] = len(pool.controls)
+			pool.controls = append(pool.controls, pooledControl{groups: groups, control: ctrl})
+		case pool.method == CombineUseFirst:
+			continue
+		case pool.method == CombineMerge:
+			pool.controls[i].control = mergeControl(pool.controls[i].control, ctrl)
+		default:
+			return fmt.Errorf("unsupported combine method %s", pool.method)
+		}
+	}
+	return nil
+}
+
+// asIsGroups structures controls in the groups they have in their source catalogs. Controls
+// which are not in any group are returned apart.
+func asIsGroups(pool []pooledControl) ([]catalog.Group, []catalog.Control) {
+	var groups []catalog.Group
+	var controls []catalog.Control
+	for _, pc := range pool {
+		if len(pc.groups) == 0 {
+			controls = append(controls, pc.control)
+			continue
+		}
+		level := &groups
+		var g *catalog.Group
+		for _, source := range pc.groups {
+			g = asIsGroup(level, source)
+			level = &g.Groups
+		}
+		g.Controls = append(g.Controls, pc.control)
+	}
+	return groups, controls
+}
+
+// asIsGroup finds the group matching a source group among groups, adding it without controls when missing.
+// Groups are matched by id, or by title when they have none.
+func asIsGroup(groups *[]catalog.Group, source catalog.Group) *catalog.Group {
+	for i := range *groups {
+		if groupKey((*groups)[i]) == groupKey(source) {
+			return &(*groups)[i]
+		}
+	}
+	g := source
+	g.Controls = []catalog.Control{}
+	g.Groups = nil
+	*groups = append(*groups, g)
+	return &(*groups)[len(*groups)-1]
+}
+
+func groupKey(g catalog.Group) string {
+	if g.Id != "" {
+		return g.Id
+	}
+	return string(g.Title)
+}
+
+func customGroups(pool []pooledControl, profileGroups []profile.Group) ([]catalog.Group, error) {
+	var groups []catalog.Group
+	for _, pg := range profileGroups {
+		controls, err := customControls(pool, pg.IdSelectors, pg.PatternSelectors)
+		if err != nil {
+			return nil, err
+		}
+		subgroups, err := customGroups(pool, pg.Groups)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, catalog.Group{
+			Controls: controls,
+			Groups:   subgroups,
+		})
+	}
+	return groups, nil
+}
+
+// customControls picks the pooled controls called or matched in a custom merge. Calling or
+// matching a subcontrol places the control it belongs to.
+func customControls(pool []pooledControl, calls []profile.Call, matches []profile.Match) ([]catalog.Control, error) {
+	var controls []catalog.Control
+	placed := make(map[int]bool)
+	place := func(i int) {
+		if placed[i] {
+			return
+		}
+		placed[i] = true
+		controls = append(controls, pool[i].control)
+	}
+	for _, call := range calls {
+		for i, pc := range pool {
+			if strings.EqualFold(pc.control.Id, call.ControlId) {
+				place(i)
+				continue
+			}
+			for _, sc := range pc.control.Subcontrols {
+				if call.SubcontrolId != "" && strings.EqualFold(sc.Id, call.SubcontrolId) {
+					place(i)
+				}
+			}
+		}
+	}
+	for _, match := range matches {
+		regex, err := compileMatch(match)
+		if err != nil {
+			return nil, err
+		}
+		var matched []int
+		for i, pc := range pool {
+			if regex.MatchString(pc.control.Id) {
+				matched = append(matched, i)
+				continue
+			}
+			for _, sc := range pc.control.Subcontrols {
+				if regex.MatchString(sc.Id) {
+					matched = append(matched, i)
+					break
+				}
+			}
+		}
+		switch match.Order {
+		case OrderAscending:
+			sort.SliceStable(matched, func(a, b int) bool {
+				return idLess(pool[matched[a]].control.Id, pool[matched[b]].control.Id)
+			})
+		case OrderDescending:
+			sort.SliceStable(matched, func(a, b int) bool {
+				return idLess(pool[matched[b]].control.Id, pool[matched[a]].control.Id)
+			})
+		}
+		for _, i := range matched {
+			place(i)
+		}
+	}
+	return controls, nil
+}
+
+// idLess orders ids such as ac-2 and ac-10 by comparing their runs of digits as numbers
+func idLess(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		ra, rb := leadingRun(a), leadingRun(b)
+		a, b = a[len(ra):], b[len(rb):]
+		if ra == rb {
+			continue
+		}
+		na, errA := strconv.Atoi(ra)
+		nb, errB := strconv.Atoi(rb)
+		if errA == nil && errB == nil && na != nb {
+			return na < nb
+		}
+		return ra < rb
+	}
+	return len(a) < len(b)
+}
+
+// leadingRun is the leading run of digits or of other characters of s
+func leadingRun(s string) string {
+	digit := unicode.IsDigit(rune(s[0]))
+	for i, r := range s {
+		if unicode.IsDigit(r) != digit {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// mergeControl merges the contents of two instances of a control. Items with an id are
+// merged by id, other items are appended unless already present.
+func mergeControl(a, b catalog.Control) catalog.Control {
+	if a.Title == "" {
+		a.Title = b.Title
+	}
+	if a.Class == "" {
+		a.Class = b.Class
+	}
+	if a.References == nil {
+		a.References = b.References
+	}
+	a.Props = mergeProps(a.Props, b.Props)
+	a.Links = mergeLinks(a.Links, b.Links)
+	a.Params = mergeParams(a.Params, b.Params)
+	a.Parts = mergeParts(a.Parts, b.Parts)
+	a.Subcontrols = append([]catalog.Subcontrol{}, a.Subcontrols...)
+	for _, sc := range b.Subcontrols {
+		found := false
+		for i := range a.Subcontrols {
+			if strings.EqualFold(a.Subcontrols[i].Id, sc.Id) {
+				a.Subcontrols[i] = mergeSubcontrol(a.Subcontrols[i], sc)
+				found = true
+				break
+			}
+		}
+		if !found {
+			a.Subcontrols = append(a.Subcontrols, sc)
+		}
+	}
+	return a
+}
+
+func mergeSubcontrol(a, b catalog.Subcontrol) catalog.Subcontrol {
+	if a.Title == "" {
+		a.Title = b.Title
+	}
+	if a.Class == "" {
+		a.Class = b.Class
+	}
+	if a.References == nil {
+		a.References = b.References
+	}
+	a.Props = mergeProps(a.Props, b.Props)
+	a.Links = mergeLinks(a.Links, b.Links)
+	a.Params = mergeParams(a.Params, b.Params)
+	a.Parts = mergeParts(a.Parts, b.Parts)
+	return a
+}
+
+func mergeProps(a, b []catalog.Prop) []catalog.Prop {
+	a = append([]catalog.Prop{}, a...)
+	for _, prop := range b {
+		found := false
+		for _, existing := range a {
+			if existing == prop {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, prop)
+		}
+	}
+	return a
+}
+
+func mergeLinks(a, b []catalog.Link) []catalog.Link {
+	a = append([]catalog.Link{}, a...)
+	for _, link := range b {
+		found := false
+		for _, existing := range a {
+			if existing.Rel == link.Rel && existing.Value == link.Value && hrefString(existing.Href) == hrefString(link.Href) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, link)
+		}
+	}
+	return a
+}
+
+func mergeParams(a, b []catalog.Param) []catalog.Param {
+	a = append([]catalog.Param{}, a...)
+	for _, param := range b {
+		found := false
+		for _, existing := range a {
+			if existing.Id == param.Id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, param)
+		}
+	}
+	return a
+}
+
+func mergeParts(a, b []catalog.Part) []catalog.Part {
+	a = append([]catalog.Part{}, a...)
+	for _, part := range b {
+		found := false
+		for i, existing := range a {
+			if part.Id != "" && existing.Id == part.Id {
+				a[i].Parts = mergeParts(existing.Parts, part.Parts)
+				found = true
+				break
+			}
+			if part.Id == "" && reflect.DeepEqual(existing, part) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, part)
+		}
+	}
+	return a
+}
+
+func hrefString(h catalog.Href) string {
+	if h.URL == nil {
+		return ""
+	}
+	return h.String()
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultMaxImportDepth is the maximum depth of a profile's import chain when none is configured
+	DefaultMaxImportDepth = 32
+	// DefaultConcurrency is the number of imports resolved at once when none is configured
+	DefaultConcurrency = 4
+)
+
+// Policies for alters and set-params of an import chain competing for the same target
+const (
+	// ConflictNearestWins applies the modifications of the profile the fewest imports away from
+	// the resolved one, warning about the others
+	ConflictNearestWins = "nearest-wins"
+	// ConflictFail fails the resolution with a ConflictError
+	ConflictFail = "fail"
+)
+
+// Options configures the resolution of a profile
+type Options struct {
+	// Href locates the resolved profile. It starts the import chain and is recorded as the
+	// source of the profile's own modifications.
+	Href string
+	// Concurrency bounds the number of imports resolved at once. DefaultConcurrency is used
+	// when it is not set.
+	Concurrency int
+	// MaxImportDepth limits the number of imports followed from the resolved profile down to a
+	// catalog. DefaultMaxImportDepth is used when it is not set.
+	MaxImportDepth int
+	// Fetcher fetches the imported documents. Without one, http(s) imports are downloaded
+	// uncached and other imports are read from the local filesystem.
+	Fetcher Fetcher
+	// ConflictPolicy tells what to do when profiles of the import chain alter the same item of a
+	// control or set the same parameter differently. ConflictNearestWins is used when it is not set.
+	ConflictPolicy string
+	// Cache memoizes the documents read and the catalogs resolved. Nothing is cached when it is
+	// not set.
+	Cache *Cache
+}
+
+func (o Options) fetcher() Fetcher {
+	if o.Fetcher == nil {
+		return NewFetcher("", false)
+	}
+	return o.Fetcher
+}
+
+func (o Options) documents() documents {
+	return documents{fetcher: o.fetcher(), cache: o.Cache}
+}
+
+func (o Options) conflictPolicy() (string, error) {
+	switch o.ConflictPolicy {
+	case "":
+		return ConflictNearestWins, nil
+	case ConflictNearestWins, ConflictFail:
+		return o.ConflictPolicy, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %s, expected %s or %s", o.ConflictPolicy, ConflictNearestWins, ConflictFail)
+}
+
+func (o Options) concurrency() int {
+	if o.Concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return o.Concurrency
+}
+
+func (o Options) maxImportDepth() int {
+	if o.MaxImportDepth <= 0 {
+		return DefaultMaxImportDepth
+	}
+	return o.MaxImportDepth
+}
+
+// importChain is the path of hrefs followed from the resolved profile to the document being imported
+type importChain struct {
+	hrefs    []string
+	depth    int
+	maxDepth int
+}
+
+// newImportChain starts an import chain at the resolved profile. The root href may be empty when
+// the location of the profile is not known.
+func newImportChain(root string, opts Options) importChain {
+	chain := importChain{maxDepth: opts.maxImportDepth()}
+	if root != "" {
+		chain.hrefs = []string{root}
+	}
+	return chain
+}
+
+// href is the document at the end of the chain, empty for a resolved profile of unknown location
+func (c importChain) href() string {
+	if len(c.hrefs) == 0 {
+		return ""
+	}
+	return c.hrefs[len(c.hrefs)-1]
+}
+
+// follow extends the chain with an import, failing when the import closes a cycle or goes
+// deeper than allowed
+func (c importChain) follow(href string) (importChain, error) {
+	for i, visited := range c.hrefs {
+		if visited == href {
+			cycle := append(append([]string{}, c.hrefs[i:]...), href)
+			return importChain{}, fmt.Errorf("import cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	if c.depth >= c.maxDepth {
+		return importChain{}, fmt.Errorf("import of %s exceeds the maximum import depth of %d", href, c.maxDepth)
+	}
+	return importChain{
+		hrefs:    append(append([]string{}, c.hrefs...), href),
+		depth:    c.depth + 1,
+		maxDepth: c.maxDepth,
+	}, nil
+}
@@ -1,88 +1,200 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
-	"sync"
+	"reflect"
+	"sort"
+	"strings"
 
-	"github.com/docker/oscalkit/types/oscal"
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
 )
 
-// HTTPFilePath map of http resource against filepath to lessen downloads
-type HTTPFilePath struct {
-	sync.Mutex
-	m map[string]string
+// sourcedAlter is an alter along with the href of the profile declaring it
+type sourcedAlter struct {
+	alter  profile.Alter
+	source string
+	// depth is the number of imports from the resolved profile to the one declaring the alter
+	depth int
 }
 
-var pathmap = HTTPFilePath{
-	m: make(map[string]string),
+// sourcedSetParam is a parameter setting along with the href of the profile declaring it
+type sourcedSetParam struct {
+	setParam profile.SetParam
+	source   string
+	depth    int
 }
 
-func findAlter(p *profile.Profile, call profile.Call) (*profile.Alter, bool, error) {
+// modifications are the alters and set-params declared up the import chain of a profile. They
+// are in precedence order: the profile's own first, then those of the profiles fewer imports away
+// from it, profiles as many imports away coming in the order they are imported.
+type modifications struct {
+	alters    []sourcedAlter
+	setParams []sourcedSetParam
+}
 
-	if p.Modify == nil {
-		p.Modify = &profile.Modify{
-			Alterations:   []profile.Alter{},
-			ParamSettings: []profile.SetParam{},
-		}
+// collectModifications collects the modifications of a profile and of its import chain in
+// precedence order
+func collectModifications(ctx context.Context, p *profile.Profile, chain importChain, docs documents) (modifications, error) {
+	var m modifications
+	depths := make(map[string]int)
+	if err := findModifications(ctx, p, chain, docs, &m, depths); err != nil {
+		return modifications{}, err
+	}
+	// the resolved profile has no depth recorded, being no import
+	for i := range m.alters {
+		m.alters[i].depth = depths[m.alters[i].source]
+	}
+	for i := range m.setParams {
+		m.setParams[i].depth = depths[m.setParams[i].source]
 	}
-	for _, alt := range p.Modify.Alterations {
-		if EquateAlter(alt, call) {
-			return &alt, true, nil
+	sort.SliceStable(m.alters, func(i, j int) bool { return m.alters[i].depth < m.alters[j].depth })
+	sort.SliceStable(m.setParams, func(i, j int) bool { return m.setParams[i].depth < m.setParams[j].depth })
+	return m, nil
+}
+
+// findModifications collects the modifications of a profile followed by those found up its import
+// chain, depth first, recording in depths the fewest imports from the resolved profile to each
+// imported one. A profile imported several times is only collected once.
+func findModifications(ctx context.Context, p *profile.Profile, chain importChain, docs documents, m *modifications, depths map[string]int) error {
+
+	if p.Modify != nil {
+		for _, alt := range p.Modify.Alterations {
+			m.alters = append(m.alters, sourcedAlter{alter: alt, source: chain.href()})
+		}
+		for _, sp := range p.Modify.ParamSettings {
+			m.setParams = append(m.setParams, sourcedSetParam{setParam: sp, source: chain.href()})
 		}
 	}
+	var failed Errors
 	for _, imp := range p.Imports {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		err := ValidateHref(imp.Href)
 		if err != nil {
-			return nil, false, err
-		}
-		path := imp.Href.String()
-		if isHTTPResource(imp.Href.URL) {
-			pathmap.Lock()
-			if v, ok := pathmap.m[imp.Href.String()]; !ok {
-				path, err = GetFilePath(imp.Href.String())
-				if err != nil {
-					return nil, false, err
-				}
-				pathmap.m[imp.Href.String()] = path
-			} else {
-				path = v
-			}
-			pathmap.Unlock()
+			return err
+		}
+		err = importedModifications(ctx, imp, chain, docs, m, depths)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
 		}
-		f, err := os.Open(path)
 		if err != nil {
-			return nil, false, err
+			failed = append(failed, &ImportError{Href: imp.Href.String(), Err: err})
 		}
-		defer f.Close()
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
 
-		o, err := oscal.New(f)
-		if err != nil {
-			return nil, false, err
+// importedModifications collects the modifications up the import chain of an imported profile, if the import is one
+func importedModifications(ctx context.Context, imp profile.Import, chain importChain, docs documents, m *modifications, depths map[string]int) error {
+	href := imp.Href.String()
+	next, err := chain.follow(href)
+	if err != nil {
+		return err
+	}
+	depth, collected := depths[href]
+	if collected && depth <= next.depth {
+		return nil
+	}
+	depths[href] = next.depth
+	o, _, err := docs.read(ctx, href)
+	if err != nil {
+		return err
+	}
+	if o.Profile == nil {
+		return nil
+	}
+	// documents may be shared with other resolutions, so base paths are set on a copy
+	importedProfile, err := SetBasePath(o.Profile.DeepCopy(), href)
+	if err != nil {
+		return err
+	}
+	if collected {
+		// imported nearer than first found, only the depths of its import chain change
+		return findModifications(ctx, importedProfile, next, docs, &modifications{}, depths)
+	}
+	return findModifications(ctx, importedProfile, next, docs, m, depths)
+}
+
+// nearestWins keeps the modifications of an import chain which apply together. The alters of
+// several profiles on the same control or subcontrol all apply, unless they compete: they remove
+// the same items, or add a different title, or add a prop of the same class, or a param or a part
+// of the same id, differently. Of competing removals and additions only those of the profile first
+// in precedence order are kept, and the others are reported as conflicts. Additions made the same
+// way by several profiles are kept once. Settings of the same parameter by several profiles
+// compete as a whole the same way.
+func (m modifications) nearestWins() (modifications, []Conflict) {
+	var kept modifications
+	var conflicts []Conflict
+
+	var targets []string
+	competitions := make(map[string]*competition)
+	for _, sa := range m.alters {
+		target := alterTarget(sa.alter)
+		c, ok := competitions[target]
+		if !ok {
+			c = newCompetition(alterID(sa.alter))
+			competitions[target] = c
+			targets = append(targets, target)
 		}
-		if o.Profile == nil {
-			continue
+		if alt, ok := c.keep(sa); ok {
+			kept.alters = append(kept.alters, sourcedAlter{alter: alt, source: sa.source, depth: sa.depth})
 		}
-		p, err = SetBasePath(o.Profile, imp.Href.String())
-		if err != nil {
-			return nil, false, err
+	}
+	for _, target := range targets {
+		if c := competitions[target]; len(c.competing) > 0 {
+			conflicts = append(conflicts, Conflict{Kind: ConflictAlter, Target: c.id, Alters: c.competing})
 		}
-		o.Profile = p
-		alt, found, err := findAlter(o.Profile, call)
-		if err != nil {
-			return nil, false, err
+	}
+
+	var params []string
+	settings := make(map[string][]sourcedSetParam)
+	for _, sp := range m.setParams {
+		id := sp.setParam.Id
+		if _, ok := settings[id]; !ok {
+			params = append(params, id)
+			kept.setParams = append(kept.setParams, sp)
+		}
+		settings[id] = append(settings[id], sp)
+	}
+	for _, id := range params {
+		conflicting := false
+		for _, sp := range settings[id][1:] {
+			if sp.source != settings[id][0].source && !reflect.DeepEqual(sp.setParam, settings[id][0].setParam) {
+				conflicting = true
+			}
 		}
-		if !found {
+		if !conflicting {
 			continue
 		}
-		return alt, true, nil
+		c := Conflict{Kind: ConflictSetParam, Target: id}
+		for _, sp := range settings[id] {
+			c.SetParams = append(c.SetParams, CompetingSetParam{Source: sp.source, SetParam: sp.setParam})
+		}
+		conflicts = append(conflicts, c)
+	}
+	return kept, conflicts
+}
+
+func alterTarget(alt profile.Alter) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s", alt.ControlId, alt.SubcontrolId))
+}
+
+// alterID is the id of the control or subcontrol targeted by an alter
+func alterID(alt profile.Alter) string {
+	if alt.ControlId != "" {
+		return alt.ControlId
 	}
-	return nil, false, nil
+	return alt.SubcontrolId
 }
 
 // EquateAlter equates alter with call
@@ -98,40 +210,52 @@ func EquateAlter(alt profile.Alter, call profile.Call) bool {
 }
 
 // GetAlters gets alter attributes from import chain
+// Removals and additions competing with those of a nearer profile are left out.
 func GetAlters(p *profile.Profile) ([]profile.Alter, error) {
+	m, err := collectModifications(context.Background(), p, newImportChain("", Options{}), Options{}.documents())
+	if err != nil {
+		return nil, err
+	}
+	kept, _ := m.nearestWins()
+	return alters(kept.alters), nil
+}
 
-	var alterations []profile.Alter
-	for _, i := range p.Imports {
-		for _, call := range i.Include.IdSelectors {
-			found := false
-			if p.Modify == nil {
-				p.Modify = &profile.Modify{
-					Alterations:   []profile.Alter{},
-					ParamSettings: []profile.SetParam{},
-				}
-			}
-			for _, alt := range p.Modify.Alterations {
-				if EquateAlter(alt, call) {
-					alterations = append(alterations, alt)
-					found = true
-					break
-				}
-			}
-			if !found {
-				alt, found, err := findAlter(p, call)
-				if err != nil {
-					return nil, err
-				}
-				if !found {
-					continue
-				}
-				alterations = append(alterations, *alt)
-			}
-
+// declaredBy gives the modifications declared by the profiles at hrefs, in precedence order
+func (m modifications) declaredBy(hrefs ...string) modifications {
+	declaring := make(map[string]bool, len(hrefs))
+	for _, href := range hrefs {
+		declaring[href] = true
+	}
+	var declared modifications
+	for _, sa := range m.alters {
+		if declaring[sa.source] {
+			declared.alters = append(declared.alters, sa)
 		}
 	}
-	return alterations, nil
+	for _, sp := range m.setParams {
+		if declaring[sp.source] {
+			declared.setParams = append(declared.setParams, sp)
+		}
+	}
+	return declared
+}
 
+// alters and setParams copy the modifications to apply, so that the catalogs they are applied
+// to share nothing with the profiles they come from
+func alters(sourced []sourcedAlter) []profile.Alter {
+	alterations := make([]profile.Alter, 0, len(sourced))
+	for _, sa := range sourced {
+		alterations = append(alterations, *sa.alter.DeepCopy())
+	}
+	return alterations
+}
+
+func setParams(sourced []sourcedSetParam) []profile.SetParam {
+	settings := make([]profile.SetParam, 0, len(sourced))
+	for _, sp := range sourced {
+		settings = append(settings, *sp.setParam.DeepCopy())
+	}
+	return settings
 }
 
 // SetBasePath sets up base paths for profiles
@@ -158,8 +282,7 @@ func SetBasePath(p *profile.Profile, parentPath string) (*profile.Profile, error
 			p.Imports[i].Href = &catalog.Href{URL: url}
 			continue
 		}
-		path := fmt.Sprintf("%s/%s", path.Dir(parentPath), path.Base(x.Href.String()))
-		path, err = filepath.Abs(path)
+		path, err := filepath.Abs(importPath(parentPath, x.Href.String()))
 		if err != nil {
 			return nil, err
 		}
@@ -172,6 +295,19 @@ func SetBasePath(p *profile.Profile, parentPath string) (*profile.Profile, error
 	return p, nil
 }
 
+// importPath gives the path of a local import. Relative hrefs are relative to the directory of the
+// importing profile. Absolute hrefs of missing files are read as rooted at that directory, the way
+// published baselines refer to the catalog next to them.
+func importPath(parentPath, href string) string {
+	if !path.IsAbs(href) {
+		return path.Join(path.Dir(parentPath), href)
+	}
+	if _, err := os.Stat(href); err == nil {
+		return href
+	}
+	return path.Join(path.Dir(parentPath), path.Base(href))
+}
+
 func makeURL(url, child *url.URL) (*url.URL, error) {
 	newURL, err := url.Parse(fmt.Sprintf("%s://%s%s/%s", url.Scheme, url.Host, path.Dir(url.Path), child.String()))
 	if err != nil {
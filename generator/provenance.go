@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/sirupsen/logrus"
+)
+
+// Link relations recording where the controls of a resolved catalog come from
+const (
+	// RelSourceCatalog links a control to the catalog it was taken from
+	RelSourceCatalog = "source-catalog"
+	// RelImport links a control to the profile import which selected it
+	RelImport = "import"
+	// RelAlter links a control or subcontrol to the profile which altered it
+	RelAlter = "alter"
+	// RelSetParam links a control or subcontrol to the profile which set one of its parameters
+	RelSetParam = "set-param"
+)
+
+// provenance describes the origin of the controls mapped for a profile import
+type provenance struct {
+	source     string
+	importHref string
+	alters     []sourcedAlter
+	setParams  []sourcedSetParam
+}
+
+func addProvenance(c *catalog.Catalog, p provenance) {
+	catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, _ []catalog.Node) error {
+		switch {
+		case n.Control != nil:
+			addControlProvenance(n.Control, p)
+		case n.Subcontrol != nil:
+			addSubcontrolProvenance(n.Subcontrol, p)
+		case n.Part != nil:
+			return catalog.SkipChildren
+		}
+		return nil
+	}))
+}
+
+func addControlProvenance(ctrl *catalog.Control, p provenance) {
+	links := []catalog.Link{
+		provenanceLink(RelSourceCatalog, p.source, ""),
+		provenanceLink(RelImport, p.importHref, ""),
+	}
+	for _, sa := range p.alters {
+		if sa.alter.ControlId != "" && strings.EqualFold(sa.alter.ControlId, ctrl.Id) {
+			links = append(links, provenanceLink(RelAlter, sa.source, ""))
+		}
+	}
+	links = append(links, setParamLinks(ctrl.Params, p)...)
+	ctrl.Links = append(append([]catalog.Link{}, ctrl.Links...), links...)
+}
+
+func addSubcontrolProvenance(sc *catalog.Subcontrol, p provenance) {
+	var links []catalog.Link
+	for _, sa := range p.alters {
+		if sa.alter.SubcontrolId != "" && strings.EqualFold(sa.alter.SubcontrolId, sc.Id) {
+			links = append(links, provenanceLink(RelAlter, sa.source, ""))
+		}
+	}
+	links = append(links, setParamLinks(sc.Params, p)...)
+	sc.Links = append(append([]catalog.Link{}, sc.Links...), links...)
+}
+
+// setParamLinks links the parameters to the profiles setting them, naming the parameter in the link text
+func setParamLinks(params []catalog.Param, p provenance) []catalog.Link {
+	var links []catalog.Link
+	for _, sp := range p.setParams {
+		for _, param := range params {
+			if param.Id == sp.setParam.Id {
+				links = append(links, provenanceLink(RelSetParam, sp.source, sp.setParam.Id))
+			}
+		}
+	}
+	return links
+}
+
+func provenanceLink(rel, href, value string) catalog.Link {
+	link := catalog.Link{Rel: rel, Value: value}
+	if href == "" {
+		return link
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		logrus.Warnf("cannot parse href %s for %s link: %v", href, rel, err)
+		return link
+	}
+	link.Href = catalog.Href{URL: u}
+	return link
+}
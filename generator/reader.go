@@ -1,24 +1,17 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/docker/oscalkit/types/oscal"
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
-	"github.com/sirupsen/logrus"
-)
-
-const (
-	tempDir = "/tmp/"
 )
 
 // ReadCatalog ReadCatalog
@@ -50,9 +43,9 @@ func ReadProfile(r io.Reader) (*profile.Profile, error) {
 	return o.Profile, nil
 }
 
-// GetFilePath GetFilePath
+// GetFilePath gets the local path of a file or URL. Documents over http(s) are downloaded into
+// a cache under the temporary directory, named after the hash of their URL.
 func GetFilePath(URL string) (string, error) {
-	t := time.Now()
 	uri, err := url.Parse(URL)
 	if err != nil {
 		return "", fmt.Errorf("invalid URL pattern %v", err)
@@ -61,25 +54,14 @@ func GetFilePath(URL string) (string, error) {
 	if !isHTTPResource(uri) {
 		return GetAbsolutePath(URL)
 	}
-	logrus.Debugf("fetching from http resource %s", uri.String())
-	body, err := fetchFromHTTPResource(uri)
-	if err != nil {
-		return "", fmt.Errorf("cannot fetch from url %v", err)
-	}
-
-	fileName := tempDir + getName(uri)
-	f, err := os.Create(fileName)
+	fetcher := HTTPFetcher{CacheDir: filepath.Join(os.TempDir(), "oscalkit")}
+	r, err := fetcher.Fetch(context.Background(), uri.String())
 	if err != nil {
-		return "", fmt.Errorf("cannot create json file %v", err)
+		return "", err
 	}
-	defer f.Close()
-	_, err = f.Write(body)
-	if err != nil {
-		return "", fmt.Errorf("cannot write on file %v", err)
-	}
-	logrus.Debugf("file downloaded in %f seconds.", time.Since(t).Seconds())
-	return fileName, nil
-
+	r.Close()
+	path, _ := fetcher.cachePaths(uri.String())
+	return path, nil
 }
 
 // GetAbsolutePath gets absolute file path
@@ -93,23 +75,3 @@ func GetAbsolutePath(path string) (string, error) {
 func isHTTPResource(url *url.URL) bool {
 	return strings.Contains(url.Scheme, "http")
 }
-
-func getName(url *url.URL) string {
-	fragments := strings.Split(url.Path, "/")
-	return (fragments[len(fragments)-1])
-}
-
-func fetchFromHTTPResource(uri *url.URL) ([]byte, error) {
-	c := http.Client{Timeout: 10 * time.Second}
-	resp, err := c.Get(uri.String())
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("cannot read response body %v", err)
-	}
-	return body, nil
-
-}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+	"github.com/sirupsen/logrus"
+)
+
+// Resolve resolves a profile into a single catalog as per the profile's merge directive.
+// Every control links back to its source catalog, the import of the profile which selected it
+// and the profiles whose alters and set-params were applied to it.
+//
+// Imports are resolved concurrently by at most Options.Concurrency workers, all of which are
+// done by the time Resolve returns. Resolution stops when ctx is cancelled or times out, and
+// the failures of several imports are reported together as Errors.
+//
+// With Options.Cache, a profile resolved before along with the same import chain is not resolved
+// again.
+func Resolve(ctx context.Context, profileArg *profile.Profile, opts Options) (*catalog.Catalog, error) {
+	catalogs, err := memoize(ctx, "resolve", profileArg, opts, func() ([]*catalog.Catalog, error) {
+		r, err := resolve(ctx, profileArg, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*catalog.Catalog{r.catalog}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogs) != 1 {
+		return nil, fmt.Errorf("expected one resolved catalog, got %d", len(catalogs))
+	}
+	return catalogs[0], nil
+}
+
+// Conflicts reports the alters and set-params of a profile's import chain which compete for the
+// same item of a control or subcontrol, or for the same parameter, whatever the conflict policy
+// of opts.
+func Conflicts(ctx context.Context, profileArg *profile.Profile, opts Options) ([]Conflict, error) {
+	m, err := collectModifications(ctx, profileArg, newImportChain(opts.Href, opts), opts.documents())
+	if err != nil {
+		return nil, err
+	}
+	_, conflicts := m.nearestWins()
+	return conflicts, nil
+}
+
+// resolution is the outcome of resolving a profile along with what it was made of
+type resolution struct {
+	catalog   *catalog.Catalog
+	imports   []mappedImport
+	alters    []sourcedAlter
+	setParams []sourcedSetParam
+	conflicts []Conflict
+}
+
+func resolve(ctx context.Context, profileArg *profile.Profile, opts Options) (*resolution, error) {
+	imports, applied, conflicts, err := mapProfile(ctx, profileArg, opts)
+	if err != nil {
+		return nil, err
+	}
+	catalogs := make([]*catalog.Catalog, 0, len(imports))
+	for _, mi := range imports {
+		// only the modifications along the import's own chains were applied to its catalog
+		declared := applied.declaredBy(mi.modifiedBy...)
+		addProvenance(mi.catalog, provenance{
+			source:     mi.source,
+			importHref: mi.profileImport.Href.String(),
+			alters:     declared.alters,
+			setParams:  declared.setParams,
+		})
+		catalogs = append(catalogs, mi.catalog)
+	}
+	merged, err := MergeCatalogs(profileArg.Merge, catalogs)
+	if err != nil {
+		return nil, err
+	}
+	return &resolution{
+		catalog:   merged,
+		imports:   imports,
+		alters:    applied.alters,
+		setParams: applied.setParams,
+		conflicts: conflicts,
+	}, nil
+}
+
+// mapProfile maps the imports of a profile once the modifications of its import chain are
+// applied, as per the conflict policy of opts
+func mapProfile(ctx context.Context, profileArg *profile.Profile, opts Options) ([]mappedImport, modifications, []Conflict, error) {
+	policy, err := opts.conflictPolicy()
+	if err != nil {
+		return nil, modifications{}, nil, err
+	}
+	chain := newImportChain(opts.Href, opts)
+	logrus.Info("fetching alterations...")
+	m, err := collectModifications(ctx, profileArg, chain, opts.documents())
+	if err != nil {
+		return nil, modifications{}, nil, err
+	}
+	logrus.Info("fetching alterations from import chain complete")
+	applied, conflicts := m.nearestWins()
+	if len(conflicts) > 0 && policy == ConflictFail {
+		return nil, modifications{}, nil, &ConflictError{Conflicts: conflicts}
+	}
+	for _, c := range conflicts {
+		logrus.Warnf("%s, applying the nearest one", c)
+	}
+	imports, err := mapImports(ctx, profileArg, applied, chain, opts)
+	if err != nil {
+		return nil, modifications{}, nil, err
+	}
+	return imports, applied, conflicts, nil
+}
@@ -0,0 +1,245 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/docker/oscalkit/impl"
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// selection holds the (lower cased) ids of the controls and subcontrols selected by a profile import
+type selection struct {
+	controls    map[string]bool
+	subcontrols map[string]bool
+	// includedBy and excludedBy describe the selectors including and excluding each id
+	includedBy map[string][]string
+	excludedBy map[string][]string
+}
+
+// catalogIndex looks up the controls and subcontrols of a catalog for selection
+type catalogIndex struct {
+	*catalog.Index
+	// scheme is the control-id scheme of the catalog, nil when none of the registered ones applies
+	scheme impl.Scheme
+}
+
+func newSelection() selection {
+	return selection{
+		controls:    make(map[string]bool),
+		subcontrols: make(map[string]bool),
+		includedBy:  make(map[string][]string),
+		excludedBy:  make(map[string][]string),
+	}
+}
+
+func newCatalogIndex(c *catalog.Catalog) catalogIndex {
+	return catalogIndex{Index: catalog.NewIndex(c), scheme: impl.DetectScheme(c)}
+}
+
+// parent gives the (lower cased) id of the control of a subcontrol
+func (index catalogIndex) parent(id string) (string, bool) {
+	if index.Subcontrol(id) == nil {
+		return "", false
+	}
+	parent, _ := index.Parent(id)
+	return strings.ToLower(parent.ID()), true
+}
+
+// key gives the lookup key of an id referring to a control or subcontrol of the catalog, which
+// may be written in another notation of the catalog's scheme, such as AC-2(1) for ac-2.1
+func (index catalogIndex) key(id string) string {
+	key := strings.ToLower(id)
+	if index.Control(key) != nil || index.Subcontrol(key) != nil || index.scheme == nil {
+		return key
+	}
+	return strings.ToLower(index.scheme.Normalize(id))
+}
+
+// isYes checks the value of yes/no flags such as with-control and with-subcontrols
+func isYes(flag string) bool {
+	return strings.ToLower(strings.TrimSpace(flag)) == "yes"
+}
+
+// isNo checks the value of yes/no flags. Flags which default to yes are only switched off explicitly
+func isNo(flag string) bool {
+	return strings.ToLower(strings.TrimSpace(flag)) == "no"
+}
+
+// selectControls resolves the include and exclude directives of an import against the imported catalog
+func selectControls(c *catalog.Catalog, profileImport profile.Import) (selection, error) {
+	return selectFromIndex(newCatalogIndex(c), profileImport)
+}
+
+// selectFromIndex resolves the include and exclude directives of an import against the index of the imported catalog
+func selectFromIndex(index catalogIndex, profileImport profile.Import) (selection, error) {
+	s := newSelection()
+
+	include := profileImport.Include
+	// an import without include directives brings in the whole catalog
+	if include == nil {
+		include = &profile.Include{All: &profile.All{WithSubcontrols: "yes"}}
+	}
+	if include.All != nil {
+		for _, ctrl := range index.Controls() {
+			s.addControl(strings.ToLower(ctrl.Id), isYes(include.All.WithSubcontrols), index, describeAll(*include.All))
+		}
+	}
+	for _, call := range include.IdSelectors {
+		if call.ControlId != "" {
+			id := index.key(call.ControlId)
+			if index.Control(id) == nil {
+				return selection{}, fmt.Errorf("could not find control %s in catalog", call.ControlId)
+			}
+			s.addControl(id, isYes(call.WithSubcontrols), index, describeCall(call))
+		}
+		if call.SubcontrolId != "" {
+			id := index.key(call.SubcontrolId)
+			if index.Subcontrol(id) == nil {
+				return selection{}, fmt.Errorf("could not find subcontrol %s in catalog", call.SubcontrolId)
+			}
+			s.addSubcontrol(id, !isNo(call.WithControl), index, describeCall(call))
+		}
+	}
+	for _, match := range include.PatternSelectors {
+		regex, err := compileMatch(match)
+		if err != nil {
+			return selection{}, err
+		}
+		for _, ctrl := range index.Controls() {
+			if id := strings.ToLower(ctrl.Id); regex.MatchString(id) {
+				s.addControl(id, isYes(match.WithSubcontrols), index, describeMatch(match))
+			}
+		}
+		for _, sc := range index.Subcontrols() {
+			if id := strings.ToLower(sc.Id); regex.MatchString(id) {
+				s.addSubcontrol(id, !isNo(match.WithControl), index, describeMatch(match))
+			}
+		}
+	}
+
+	if profileImport.Exclude == nil {
+		return s, nil
+	}
+	for _, call := range profileImport.Exclude.IdSelectors {
+		if call.ControlId != "" {
+			s.removeControl(index.key(call.ControlId), index, describeCall(call))
+		}
+		if call.SubcontrolId != "" {
+			s.removeSubcontrol(index.key(call.SubcontrolId), describeCall(call))
+		}
+	}
+	for _, match := range profileImport.Exclude.PatternSelectors {
+		regex, err := compileMatch(match)
+		if err != nil {
+			return selection{}, err
+		}
+		for _, ctrl := range index.Controls() {
+			if id := strings.ToLower(ctrl.Id); regex.MatchString(id) {
+				s.removeControl(id, index, describeMatch(match))
+			}
+		}
+		for _, sc := range index.Subcontrols() {
+			if id := strings.ToLower(sc.Id); regex.MatchString(id) {
+				s.removeSubcontrol(id, describeMatch(match))
+			}
+		}
+	}
+	return s, nil
+}
+
+// describeAll, describeCall and describeMatch describe selectors the way they are written in a profile
+func describeAll(all profile.All) string {
+	if all.WithSubcontrols == "" {
+		return "all"
+	}
+	return fmt.Sprintf("all with-subcontrols=%q", all.WithSubcontrols)
+}
+
+func describeCall(call profile.Call) string {
+	desc := "call"
+	if call.ControlId != "" {
+		desc += fmt.Sprintf(" control-id=%q", call.ControlId)
+	}
+	if call.SubcontrolId != "" {
+		desc += fmt.Sprintf(" subcontrol-id=%q", call.SubcontrolId)
+	}
+	if call.WithControl != "" {
+		desc += fmt.Sprintf(" with-control=%q", call.WithControl)
+	}
+	if call.WithSubcontrols != "" {
+		desc += fmt.Sprintf(" with-subcontrols=%q", call.WithSubcontrols)
+	}
+	return desc
+}
+
+func describeMatch(match profile.Match) string {
+	desc := fmt.Sprintf("match pattern=%q", match.Pattern)
+	if match.WithControl != "" {
+		desc += fmt.Sprintf(" with-control=%q", match.WithControl)
+	}
+	if match.WithSubcontrols != "" {
+		desc += fmt.Sprintf(" with-subcontrols=%q", match.WithSubcontrols)
+	}
+	return desc
+}
+
+func compileMatch(match profile.Match) (*regexp.Regexp, error) {
+	// patterns are matched against whole ids
+	regex, err := regexp.Compile(fmt.Sprintf("(?i)^(?:%s)$", match.Pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid match pattern %s: %v", match.Pattern, err)
+	}
+	return regex, nil
+}
+
+func (s selection) addControl(id string, withSubcontrols bool, index catalogIndex, by string) {
+	s.controls[id] = true
+	s.includedBy[id] = append(s.includedBy[id], by)
+	if !withSubcontrols {
+		return
+	}
+	for _, sc := range index.Control(id).Subcontrols {
+		scID := strings.ToLower(sc.Id)
+		s.subcontrols[scID] = true
+		s.includedBy[scID] = append(s.includedBy[scID], by)
+	}
+}
+
+func (s selection) addSubcontrol(id string, withControl bool, index catalogIndex, by string) {
+	s.subcontrols[id] = true
+	s.includedBy[id] = append(s.includedBy[id], by)
+	if withControl {
+		ctrlID, _ := index.parent(id)
+		s.controls[ctrlID] = true
+		s.includedBy[ctrlID] = append(s.includedBy[ctrlID], by)
+	}
+}
+
+// removeControl excludes a control along with all of its subcontrols
+func (s selection) removeControl(id string, index catalogIndex, by string) {
+	delete(s.controls, id)
+	s.excludedBy[id] = append(s.excludedBy[id], by)
+	ctrl := index.Control(id)
+	if ctrl == nil {
+		return
+	}
+	for _, sc := range ctrl.Subcontrols {
+		s.removeSubcontrol(strings.ToLower(sc.Id), by)
+	}
+}
+
+func (s selection) removeSubcontrol(id string, by string) {
+	delete(s.subcontrols, id)
+	s.excludedBy[id] = append(s.excludedBy[id], by)
+}
+
+func (s selection) hasControl(id string) bool {
+	return s.controls[strings.ToLower(id)]
+}
+
+func (s selection) hasSubcontrol(id string) bool {
+	return s.subcontrols[strings.ToLower(id)]
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/implementation"
 )
 
@@ -333,3 +334,224 @@ func TestDetokenizeParameterString(t *testing.T) {
 		t.Errorf("failed to tokenize parameter string %s| output %s:%s", x, profileMap[p], checkAndValue)
 	}
 }
+
+func TestSchemes(t *testing.T) {
+	tt := []struct {
+		scheme       string
+		id           string
+		matches      bool
+		control      string
+		isSubcontrol bool
+		normalized   string
+	}{
+		{NISTRev4, "ac-2", true, "ac-2", false, "ac-2"},
+		{NISTRev4, "ac-2.1", true, "ac-2", true, "ac-2.1"},
+		{NISTRev4, "AC-2 (1)", true, "ac-2", true, "ac-2.1"},
+		{NISTRev4, "AC-2(1)", false, "ac-2", true, "ac-2.1"},
+		{NISTRev4, "ac-2a", false, "ac-2", false, "ac-2_smt.a"},
+		{NISTRev4, "ac-2_smt", false, "ac-2", false, "ac-2"},
+		{NISTRev5, "AC-2(1)", true, "ac-2", true, "ac-2.1"},
+		{NISTRev5, "ac-02.01", true, "ac-2", true, "ac-2.1"},
+		{NISTRev5, "AC-2 (1)", false, "ac-2", true, "ac-2.1"},
+		{ISO27001, "A.9.2.1", true, "a.9.2.1", false, "a.9.2.1"},
+		{ISO27001, "A5.1", true, "a.5.1", false, "a.5.1"},
+		{ISO27001, "9.2.1", false, "9.2.1", false, "9.2.1"},
+		{CIS, "4", true, "4", false, "4"},
+		{CIS, "4.1", true, "4", true, "4.1"},
+		{CIS, "4.1.2", false, "4", true, "4.1.2"},
+		{PCIDSS, "8.3", true, "8.3", false, "8.3"},
+		{PCIDSS, "8.3.1", true, "8.3", true, "8.3.1"},
+		{PCIDSS, "8.3.1.A", true, "8.3", true, "8.3.1.a"},
+	}
+	for _, tc := range tt {
+		s, err := LookupScheme(tc.scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Matches(tc.id) != tc.matches {
+			t.Errorf("%s: %s should match: %t", tc.scheme, tc.id, tc.matches)
+		}
+		if c := s.Control(tc.id); c != tc.control {
+			t.Errorf("%s: control of %s should be %s, got %s", tc.scheme, tc.id, tc.control, c)
+		}
+		if s.IsSubcontrol(tc.id) != tc.isSubcontrol {
+			t.Errorf("%s: %s should be a subcontrol: %t", tc.scheme, tc.id, tc.isSubcontrol)
+		}
+		if n := s.Normalize(tc.id); n != tc.normalized {
+			t.Errorf("%s: %s should normalize to %s, got %s", tc.scheme, tc.id, tc.normalized, n)
+		}
+	}
+	if _, err := LookupScheme("nist-800-171"); err == nil {
+		t.Error("lookup of an unregistered scheme should fail")
+	}
+}
+
+func TestDetectScheme(t *testing.T) {
+	controls := func(title string, ids ...string) *catalog.Catalog {
+		c := &catalog.Catalog{Title: catalog.Title(title)}
+		for _, id := range ids {
+			c.Controls = append(c.Controls, catalog.Control{Id: id})
+		}
+		return c
+	}
+	tt := []struct {
+		catalog *catalog.Catalog
+		scheme  string
+	}{
+		{controls("NIST SP800-53", "ac-1", "ac-2"), NISTRev4},
+		{controls("NIST SP 800-53 Rev 5", "ac-1", "ac-2"), NISTRev5},
+		{controls("Annex A", "A.5.1.1", "A.5.1.2"), ISO27001},
+		{controls("Controls", "1", "1.1", "1.2"), CIS},
+		{controls("Requirements", "1.1", "1.1.1", "1.1.2"), PCIDSS},
+	}
+	for _, tc := range tt {
+		s := DetectScheme(tc.catalog)
+		if s == nil || s.Name() != tc.scheme {
+			t.Errorf("%s should be detected as %s, got %v", tc.catalog.Title, tc.scheme, s)
+		}
+	}
+	if s := DetectScheme(controls("Custom", "policy-one")); s != nil {
+		t.Errorf("no scheme should apply to custom ids, got %s", s.Name())
+	}
+}
+
+func TestDetectIDScheme(t *testing.T) {
+	tt := []struct {
+		ids    []string
+		scheme string
+	}{
+		{[]string{"ac-1", "ac-2.1", "AC-2 (3)"}, NISTRev4},
+		{[]string{"AC-2(1)", "AC-2(2)", "ac-3"}, NISTRev5},
+		{[]string{"A.9.2.1", "A.9.2.2"}, ISO27001},
+		{[]string{"8.3.1", "8.3.1.a", "8.3.2"}, PCIDSS},
+	}
+	for _, tc := range tt {
+		s := DetectIDScheme(tc.ids)
+		if s == nil || s.Name() != tc.scheme {
+			t.Errorf("%v should be detected as %s, got %v", tc.ids, tc.scheme, s)
+		}
+	}
+	if s := DetectIDScheme([]string{"policy-one"}); s != nil {
+		t.Errorf("no scheme should apply to custom ids, got %s", s.Name())
+	}
+}
+
+func TestControlIDs(t *testing.T) {
+	records := [][]string{{"title"}, {"", "component"}, {"", "", "control"}, {"", "", "ac-1"}, {"", "", ""}, {"", "", "ac-2.1"}, {""}}
+	ids := ControlIDs(records)
+	if len(ids) != 2 || ids[0] != "ac-1" || ids[1] != "ac-2.1" {
+		t.Errorf("expected the controls ac-1 and ac-2.1, got %v", ids)
+	}
+}
+
+func TestParseNISTID(t *testing.T) {
+	tt := []struct {
+		input string
+		id    NISTID
+		oscal string
+		rev4  string
+		rev5  string
+	}{
+		{"ac-2", NISTID{Family: "ac", Number: 2}, "ac-2", "AC-2", "AC-2"},
+		{"AC-2", NISTID{Family: "ac", Number: 2}, "ac-2", "AC-2", "AC-2"},
+		{" AC-02 ", NISTID{Family: "ac", Number: 2}, "ac-2", "AC-2", "AC-2"},
+		{"ac-2_smt", NISTID{Family: "ac", Number: 2}, "ac-2", "AC-2", "AC-2"},
+		{"ac-2.1", NISTID{Family: "ac", Number: 2, Enhancement: 1}, "ac-2.1", "AC-2 (1)", "AC-2(1)"},
+		{"AC-2 (1)", NISTID{Family: "ac", Number: 2, Enhancement: 1}, "ac-2.1", "AC-2 (1)", "AC-2(1)"},
+		{"AC-2(1)", NISTID{Family: "ac", Number: 2, Enhancement: 1}, "ac-2.1", "AC-2 (1)", "AC-2(1)"},
+		{"ac-02.01", NISTID{Family: "ac", Number: 2, Enhancement: 1}, "ac-2.1", "AC-2 (1)", "AC-2(1)"},
+		{"ac-2.1_smt", NISTID{Family: "ac", Number: 2, Enhancement: 1}, "ac-2.1", "AC-2 (1)", "AC-2(1)"},
+		{"ac-2_smt.a", NISTID{Family: "ac", Number: 2, Part: []string{"a"}}, "ac-2_smt.a", "AC-2a", "AC-2a"},
+		{"ac-2_smt.a.1", NISTID{Family: "ac", Number: 2, Part: []string{"a", "1"}}, "ac-2_smt.a.1", "AC-2a.1", "AC-2a.1"},
+		{"AC-2a", NISTID{Family: "ac", Number: 2, Part: []string{"a"}}, "ac-2_smt.a", "AC-2a", "AC-2a"},
+		{"AC-2a.", NISTID{Family: "ac", Number: 2, Part: []string{"a"}}, "ac-2_smt.a", "AC-2a", "AC-2a"},
+		{"AC-2 a.1.", NISTID{Family: "ac", Number: 2, Part: []string{"a", "1"}}, "ac-2_smt.a.1", "AC-2a.1", "AC-2a.1"},
+		{"AC-2(a)", NISTID{Family: "ac", Number: 2, Part: []string{"a"}}, "ac-2_smt.a", "AC-2a", "AC-2a"},
+		{"ac-2.12_smt.a", NISTID{Family: "ac", Number: 2, Enhancement: 12, Part: []string{"a"}}, "ac-2.12_smt.a", "AC-2 (12)(a)", "AC-2(12)(a)"},
+		{"ac-2.12.a", NISTID{Family: "ac", Number: 2, Enhancement: 12, Part: []string{"a"}}, "ac-2.12_smt.a", "AC-2 (12)(a)", "AC-2(12)(a)"},
+		{"AC-2 (12)(a)", NISTID{Family: "ac", Number: 2, Enhancement: 12, Part: []string{"a"}}, "ac-2.12_smt.a", "AC-2 (12)(a)", "AC-2(12)(a)"},
+		{"AC-2(12)(a)(1)", NISTID{Family: "ac", Number: 2, Enhancement: 12, Part: []string{"a", "1"}}, "ac-2.12_smt.a.1", "AC-2 (12)(a)(1)", "AC-2(12)(a)(1)"},
+		{"AC-2 (12) (a)", NISTID{Family: "ac", Number: 2, Enhancement: 12, Part: []string{"a"}}, "ac-2.12_smt.a", "AC-2 (12)(a)", "AC-2(12)(a)"},
+		{"si-4.24", NISTID{Family: "si", Number: 4, Enhancement: 24}, "si-4.24", "SI-4 (24)", "SI-4(24)"},
+		{"PM-10", NISTID{Family: "pm", Number: 10}, "pm-10", "PM-10", "PM-10"},
+	}
+	for _, tc := range tt {
+		id, err := ParseNISTID(tc.input)
+		if err != nil {
+			t.Errorf("%q: %v", tc.input, err)
+			continue
+		}
+		if fmt.Sprintf("%#v", id) != fmt.Sprintf("%#v", tc.id) {
+			t.Errorf("%q should parse as %#v, got %#v", tc.input, tc.id, id)
+		}
+		if id.String() != tc.oscal {
+			t.Errorf("%q should render as %s, got %s", tc.input, tc.oscal, id.String())
+		}
+		if id.Rev4Label() != tc.rev4 {
+			t.Errorf("%q should label as %s in rev4, got %s", tc.input, tc.rev4, id.Rev4Label())
+		}
+		if id.Rev5Label() != tc.rev5 {
+			t.Errorf("%q should label as %s in rev5, got %s", tc.input, tc.rev5, id.Rev5Label())
+		}
+		// every rendering parses back to the same identifier
+		for _, rendered := range []string{id.String(), id.Rev4Label(), id.Rev5Label()} {
+			again, err := ParseNISTID(rendered)
+			if err != nil || fmt.Sprintf("%#v", again) != fmt.Sprintf("%#v", id) {
+				t.Errorf("%q rendered as %s should parse back, got %#v, %v", tc.input, rendered, again, err)
+			}
+		}
+	}
+}
+
+func TestParseNISTIDErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"ac",
+		"ac2",
+		"a-2",
+		"acc-2",
+		"ac-",
+		"ac-0",
+		"ac-x",
+		"ac-2 (1",
+		"ac-2 (0)",
+		"ac-2(1)(a",
+		"ac-2(1)[1]",
+		"ac-2_smta",
+		"ac-2_smt.",
+		"ac-2_smt.a..1",
+		"ac-2_obj.1",
+		"ac-2_prm_1",
+		"ac-2 a b",
+		"1.1",
+	} {
+		if id, err := ParseNISTID(input); err == nil {
+			t.Errorf("%q should not parse, got %#v", input, id)
+		}
+	}
+}
+
+func TestNISTCatalogControls(t *testing.T) {
+	c := &NISTCatalog{ID: "NIST_SP-800-53"}
+	tt := []struct {
+		input        string
+		control      string
+		isSubControl bool
+	}{
+		{"AC-2", "ac-2", false},
+		{"ac-2.1", "ac-2", true},
+		{"AC-2 (1)", "ac-2", true},
+		{"AC-2(1)", "ac-2", true},
+		{"ac-2_smt.a", "ac-2", true},
+		{"AC-2a", "ac-2", true},
+		{"fp-8.5", "fp-8", true},
+	}
+	for _, tc := range tt {
+		if control := c.GetControl(tc.input); control != tc.control {
+			t.Errorf("control of %s should be %s, got %s", tc.input, tc.control, control)
+		}
+		if c.isSubControl(tc.input) != tc.isSubControl {
+			t.Errorf("%s should be a subcontrol: %t", tc.input, tc.isSubControl)
+		}
+	}
+}
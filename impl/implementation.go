@@ -120,6 +120,17 @@ func fillCDMap(CSVS [][]string, comp component, c Catalog) cdMap {
 	return comp.definition
 }
 
+// ControlIDs lists the control ids of a component excel sheet
+func ControlIDs(CSVS [][]string) []string {
+	var ids []string
+	for i := rowIndex; i < len(CSVS) && i < totalControlsInExcel; i++ {
+		if controlIndex < len(CSVS[i]) && CSVS[i][controlIndex] != "" {
+			ids = append(ids, CSVS[i][controlIndex])
+		}
+	}
+	return ids
+}
+
 // CreateComponentDefinition creates a component definition
 func CreateComponentDefinition(gm guidMap, cdm cdMap, componentConfName string, c Catalog, control, narrative, guid string, cdID string, parameterID, parameterString string) {
 
@@ -287,30 +298,19 @@ func (n *NISTCatalog) GetID() string {
 	return n.ID
 }
 
-// GetControl GetControl
+// GetControl returns the base control of a control, enhancement or statement item id
 func (*NISTCatalog) GetControl(p string) string {
-
-	p = strings.ToLower(p)
-	x := strings.Split(p, ".")
-	y := strings.Split(x[0], " ")
-	z := strings.Split(y[0], "_")
-	control := z[0]
-	controlLen := len(control)
-	isControl, _ := regexp.MatchString("([a-z][a-z]-[0-9]*)$", control)
-	if !isControl {
-		control = control[:controlLen-1]
+	id, err := ParseNISTID(p)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(p))
 	}
-	return control
+	return id.Control().String()
 }
 
+// isSubControl tells whether s refers to an enhancement or a statement item rather than a base control
 func (*NISTCatalog) isSubControl(s string) bool {
-	substrings := []string{" ", "(", "."}
-	for _, substr := range substrings {
-		if strings.Contains(s, substr) {
-			return true
-		}
-	}
-	return false
+	id, err := ParseNISTID(s)
+	return err == nil && (id.IsEnhancement() || id.IsPart())
 }
 
 // GenerateImplementationParameter GenerateImplementationParameter
@@ -0,0 +1,229 @@
+package impl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NISTID is a NIST SP 800-53 control identifier, such as AC-2 (1) or ac-2_smt.a
+type NISTID struct {
+	// Family is the lower cased family of the control, ac for AC-2
+	Family string
+	// Number is the number of the control in its family
+	Number int
+	// Enhancement is the number of the control enhancement, 0 for the base control
+	Enhancement int
+	// Part is the path to an item of the control statement, a then 1 for AC-2a.1
+	Part []string
+}
+
+// ParseNISTID parses a control identifier written in any of the notations found in catalogs,
+// spreadsheets and OpenControl files: ac-2.1, AC-2 (1), AC-2(1), ac-2.1_smt.a, AC-2a.1 or AC-2 (12)(a)
+func ParseNISTID(s string) (NISTID, error) {
+	p := nistIDParser{input: s, rest: strings.ToLower(strings.TrimSpace(s))}
+	var id NISTID
+	var err error
+	if id.Family, err = p.family(); err != nil {
+		return NISTID{}, err
+	}
+	if id.Number, err = p.number(); err != nil {
+		return NISTID{}, err
+	}
+	if id.Enhancement, err = p.enhancement(); err != nil {
+		return NISTID{}, err
+	}
+	if id.Part, err = p.part(); err != nil {
+		return NISTID{}, err
+	}
+	return id, nil
+}
+
+// IsEnhancement tells whether the identifier refers to a control enhancement or to one of its items
+func (id NISTID) IsEnhancement() bool {
+	return id.Enhancement > 0
+}
+
+// IsPart tells whether the identifier refers to an item of a control statement
+func (id NISTID) IsPart() bool {
+	return len(id.Part) > 0
+}
+
+// Control is the base control of the identifier
+func (id NISTID) Control() NISTID {
+	return NISTID{Family: id.Family, Number: id.Number}
+}
+
+// WithoutPart is the control or enhancement of the identifier, without any statement item
+func (id NISTID) WithoutPart() NISTID {
+	return NISTID{Family: id.Family, Number: id.Number, Enhancement: id.Enhancement}
+}
+
+// String gives the OSCAL form of the identifier shared by rev4 and rev5 catalogs, such as
+// ac-2, ac-2.1 or ac-2.1_smt.a
+func (id NISTID) String() string {
+	s := fmt.Sprintf("%s-%d", id.Family, id.Number)
+	if id.IsEnhancement() {
+		s += fmt.Sprintf(".%d", id.Enhancement)
+	}
+	if id.IsPart() {
+		s += "_smt." + strings.Join(id.Part, ".")
+	}
+	return s
+}
+
+// Rev4Label gives the label of the identifier in SP 800-53 rev4, such as AC-2, AC-2 (1), AC-2a.1
+// or AC-2 (12)(a)
+func (id NISTID) Rev4Label() string {
+	return id.label(" ")
+}
+
+// Rev5Label gives the label of the identifier in SP 800-53 rev5, such as AC-2, AC-2(1), AC-2a.1
+// or AC-2(12)(a)
+func (id NISTID) Rev5Label() string {
+	return id.label("")
+}
+
+func (id NISTID) label(enhancementSep string) string {
+	s := fmt.Sprintf("%s-%d", strings.ToUpper(id.Family), id.Number)
+	if !id.IsEnhancement() {
+		return s + strings.Join(id.Part, ".")
+	}
+	s += fmt.Sprintf("%s(%d)", enhancementSep, id.Enhancement)
+	for _, item := range id.Part {
+		s += fmt.Sprintf("(%s)", item)
+	}
+	return s
+}
+
+// nistIDParser consumes a lower cased identifier from left to right
+type nistIDParser struct {
+	input string
+	rest  string
+}
+
+func (p *nistIDParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid NIST control id %q: %s", p.input, fmt.Sprintf(format, args...))
+}
+
+func (p *nistIDParser) skipSpaces() {
+	p.rest = strings.TrimLeft(p.rest, " ")
+}
+
+// consume removes prefix from the rest of the identifier, reporting whether it was there
+func (p *nistIDParser) consume(prefix string) bool {
+	if !strings.HasPrefix(p.rest, prefix) {
+		return false
+	}
+	p.rest = p.rest[len(prefix):]
+	return true
+}
+
+// take removes the longest prefix of characters satisfying f
+func (p *nistIDParser) take(f func(r byte) bool) string {
+	i := 0
+	for i < len(p.rest) && f(p.rest[i]) {
+		i++
+	}
+	taken := p.rest[:i]
+	p.rest = p.rest[i:]
+	return taken
+}
+
+func isLetter(r byte) bool { return r >= 'a' && r <= 'z' }
+
+func isDigit(r byte) bool { return r >= '0' && r <= '9' }
+
+func isAlnum(r byte) bool { return isLetter(r) || isDigit(r) }
+
+func (p *nistIDParser) family() (string, error) {
+	family := p.take(isLetter)
+	if len(family) != 2 {
+		return "", p.errorf("expected a two letter family")
+	}
+	if !p.consume("-") {
+		return "", p.errorf("expected - after the family")
+	}
+	return family, nil
+}
+
+func (p *nistIDParser) positive(what string) (int, error) {
+	digits := p.take(isDigit)
+	n, err := strconv.Atoi(digits)
+	if err != nil || n == 0 {
+		return 0, p.errorf("expected a %s number", what)
+	}
+	return n, nil
+}
+
+func (p *nistIDParser) number() (int, error) {
+	return p.positive("control")
+}
+
+// enhancement reads an enhancement in dot notation, .1, or in parentheses, (1) or (1) with a space before
+func (p *nistIDParser) enhancement() (int, error) {
+	spaced := strings.TrimLeft(p.rest, " ")
+	switch {
+	case len(p.rest) > 1 && p.rest[0] == '.' && isDigit(p.rest[1]):
+		p.consume(".")
+		return p.positive("enhancement")
+	case len(spaced) > 1 && spaced[0] == '(' && isDigit(spaced[1]):
+		p.skipSpaces()
+		p.consume("(")
+		n, err := p.positive("enhancement")
+		if err != nil {
+			return 0, err
+		}
+		if !p.consume(")") {
+			return 0, p.errorf("expected ) after the enhancement")
+		}
+		return n, nil
+	}
+	return 0, nil
+}
+
+// part reads the statement item path, written _smt.a.1 as in catalogs, (a)(1) after an
+// enhancement label or a.1 after a control label, with an optional trailing dot
+func (p *nistIDParser) part() ([]string, error) {
+	p.skipSpaces()
+	var items []string
+	switch {
+	case p.rest == "":
+		return nil, nil
+	case p.consume("_smt"):
+		if p.rest == "" {
+			return nil, nil
+		}
+		if !p.consume(".") {
+			return nil, p.errorf("expected . after _smt")
+		}
+		items = strings.Split(strings.TrimSuffix(p.rest, "."), ".")
+	case strings.HasPrefix(p.rest, "("):
+		for p.consume("(") {
+			item := p.take(isAlnum)
+			if item == "" || !p.consume(")") {
+				return nil, p.errorf("expected a statement item in parentheses")
+			}
+			items = append(items, item)
+		}
+		if p.rest != "" {
+			return nil, p.errorf("unexpected %q", p.rest)
+		}
+		return items, nil
+	default:
+		p.consume(".")
+		items = strings.Split(strings.TrimSuffix(p.rest, "."), ".")
+	}
+	for _, item := range items {
+		for i := range item {
+			if !isAlnum(item[i]) {
+				return nil, p.errorf("unexpected %q", p.rest)
+			}
+		}
+		if item == "" {
+			return nil, p.errorf("empty statement item in %q", p.rest)
+		}
+	}
+	p.rest = ""
+	return items, nil
+}
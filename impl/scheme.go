@@ -0,0 +1,350 @@
+package impl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+// Names of the registered control-ID schemes
+const (
+	NISTRev4 = "nist-800-53-rev4"
+	NISTRev5 = "nist-800-53-rev5"
+	ISO27001 = "iso-27001"
+	CIS      = "cis"
+	PCIDSS   = "pci-dss"
+)
+
+// Scheme is the way a family of catalogs identifies its controls and subcontrols
+type Scheme interface {
+	// Name identifies the scheme in the registry
+	Name() string
+	// Matches tells whether an id follows the scheme
+	Matches(id string) bool
+	// Control gives the id of the control an id refers to, the id of a subcontrol giving its parent
+	Control(id string) string
+	// IsSubcontrol tells whether an id refers to a subcontrol
+	IsSubcontrol(id string) bool
+	// Normalize gives the id in the form used by the catalogs of the scheme
+	Normalize(id string) string
+	// Detect scores how well the scheme fits a catalog, zero when it does not apply at all
+	Detect(c *catalog.Catalog) int
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   []Scheme
+)
+
+func init() {
+	for _, s := range []Scheme{NISTRev4Scheme{}, NISTRev5Scheme{}, ISO27001Scheme{}, CISScheme{}, PCIDSSScheme{}} {
+		RegisterScheme(s)
+	}
+}
+
+// RegisterScheme adds a scheme to the registry, replacing any scheme of the same name.
+// Schemes registered first win detection ties.
+func RegisterScheme(s Scheme) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	for i, registered := range schemes {
+		if registered.Name() == s.Name() {
+			schemes[i] = s
+			return
+		}
+	}
+	schemes = append(schemes, s)
+}
+
+// LookupScheme finds a registered scheme by name
+func LookupScheme(name string) (Scheme, error) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	var names []string
+	for _, s := range schemes {
+		if s.Name() == name {
+			return s, nil
+		}
+		names = append(names, s.Name())
+	}
+	return nil, fmt.Errorf("unknown control-id scheme %s, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Schemes lists the names of the registered schemes
+func Schemes() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for _, s := range schemes {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// DetectScheme picks the registered scheme fitting a catalog best. It is nil when none applies.
+func DetectScheme(c *catalog.Catalog) Scheme {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	var best Scheme
+	bestScore := 0
+	for _, s := range schemes {
+		if score := s.Detect(c); score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best
+}
+
+// DetectIDScheme picks the registered scheme matching the most ids, for ids that do not come with
+// their catalog. It is nil when none applies.
+func DetectIDScheme(ids []string) Scheme {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	var best Scheme
+	bestScore := 0
+	for _, s := range schemes {
+		score := 0
+		for _, id := range ids {
+			if s.Matches(id) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+	return best
+}
+
+// countIDs counts the control and subcontrol ids of a catalog matched by a scheme
+func countIDs(c *catalog.Catalog, matches func(id string) bool) int {
+	count := 0
+	catalog.Walk(c, catalog.VisitorFunc(func(n catalog.Node, _ []catalog.Node) error {
+		switch {
+		case n.Control != nil && matches(n.Control.Id), n.Subcontrol != nil && matches(n.Subcontrol.Id):
+			count++
+		case n.Part != nil:
+			return catalog.SkipChildren
+		}
+		return nil
+	}))
+	return count
+}
+
+// titleBonus scores one more for catalogs whose title matches a pattern, breaking detection ties
+func titleBonus(c *catalog.Catalog, score int, title *regexp.Regexp) int {
+	if score > 0 && title.MatchString(string(c.Title)) {
+		return score + 1
+	}
+	return score
+}
+
+var (
+	nistID        = regexp.MustCompile(`^[a-z]{2}-\d+(\.\d+)?$`)
+	nistRev4Label = regexp.MustCompile(`^[a-z]{2}-\d+ \(\d+\)$`)
+	nistRev5Label = regexp.MustCompile(`^[a-z]{2}-\d+\(\d+\)$`)
+	nistRev4Title = regexp.MustCompile(`(?i)rev(ision)?\.?\s*4`)
+	nistRev5Title = regexp.MustCompile(`(?i)rev(ision)?\.?\s*5`)
+)
+
+func nistNormalize(id string) string {
+	parsed, err := ParseNISTID(id)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(id))
+	}
+	return parsed.String()
+}
+
+func nistControl(id string) string {
+	parsed, err := ParseNISTID(id)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(id))
+	}
+	return parsed.Control().String()
+}
+
+func nistIsSubcontrol(id string) bool {
+	parsed, err := ParseNISTID(id)
+	return err == nil && parsed.IsEnhancement()
+}
+
+// NISTRev4Scheme identifies NIST SP 800-53 revision 4 controls in dot notation, ac-2.1 for
+// the first enhancement of ac-2, also reading spreadsheet labels such as AC-2 (1)
+type NISTRev4Scheme struct{}
+
+// Name gives the name of the scheme
+func (NISTRev4Scheme) Name() string { return NISTRev4 }
+
+// Matches tells whether id is a revision 4 id or label
+func (NISTRev4Scheme) Matches(id string) bool {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return nistID.MatchString(id) || nistRev4Label.MatchString(id)
+}
+
+// Control gives the control of an id
+func (NISTRev4Scheme) Control(id string) string { return nistControl(id) }
+
+// IsSubcontrol tells whether id refers to an enhancement
+func (NISTRev4Scheme) IsSubcontrol(id string) bool { return nistIsSubcontrol(id) }
+
+// Normalize gives the id in dot notation
+func (NISTRev4Scheme) Normalize(id string) string { return nistNormalize(id) }
+
+// Detect scores the ids of a catalog in dot notation
+func (s NISTRev4Scheme) Detect(c *catalog.Catalog) int {
+	return titleBonus(c, countIDs(c, s.Matches), nistRev4Title)
+}
+
+// NISTRev5Scheme identifies NIST SP 800-53 revision 5 controls, ac-2.1 in catalogs and
+// AC-2(1) in labels
+type NISTRev5Scheme struct{}
+
+// Name gives the name of the scheme
+func (NISTRev5Scheme) Name() string { return NISTRev5 }
+
+// Matches tells whether id is a revision 5 id or label
+func (NISTRev5Scheme) Matches(id string) bool {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return nistID.MatchString(id) || nistRev5Label.MatchString(id)
+}
+
+// Control gives the control of an id
+func (NISTRev5Scheme) Control(id string) string { return nistControl(id) }
+
+// IsSubcontrol tells whether id refers to an enhancement
+func (NISTRev5Scheme) IsSubcontrol(id string) bool { return nistIsSubcontrol(id) }
+
+// Normalize gives the id in the form of revision 5 catalogs
+func (NISTRev5Scheme) Normalize(id string) string { return nistNormalize(id) }
+
+// Detect scores the ids of a catalog, preferring catalogs titled as revision 5
+func (s NISTRev5Scheme) Detect(c *catalog.Catalog) int {
+	return titleBonus(c, countIDs(c, s.Matches), nistRev5Title)
+}
+
+var (
+	isoID    = regexp.MustCompile(`^a\.?\d+(\.\d+){1,2}$`)
+	isoTitle = regexp.MustCompile(`(?i)iso.*27001`)
+)
+
+// ISO27001Scheme identifies the Annex A controls of ISO/IEC 27001, such as A.9.2.1 (2013) or
+// A.5.1 (2022). Annex A has no subcontrols.
+type ISO27001Scheme struct{}
+
+// Name gives the name of the scheme
+func (ISO27001Scheme) Name() string { return ISO27001 }
+
+// Matches tells whether id is an Annex A control
+func (ISO27001Scheme) Matches(id string) bool {
+	return isoID.MatchString(strings.ToLower(strings.TrimSpace(id)))
+}
+
+// Control gives the control of an id, which is the id itself
+func (s ISO27001Scheme) Control(id string) string { return s.Normalize(id) }
+
+// IsSubcontrol is always false as Annex A has no subcontrols
+func (ISO27001Scheme) IsSubcontrol(id string) bool { return false }
+
+// Normalize gives the id lower cased with its A. prefix
+func (ISO27001Scheme) Normalize(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if strings.HasPrefix(id, "a") && !strings.HasPrefix(id, "a.") {
+		return "a." + id[1:]
+	}
+	return id
+}
+
+// Detect scores the Annex A ids of a catalog
+func (s ISO27001Scheme) Detect(c *catalog.Catalog) int {
+	return titleBonus(c, countIDs(c, s.Matches), isoTitle)
+}
+
+var (
+	cisID    = regexp.MustCompile(`^\d+(\.\d+)?$`)
+	cisTitle = regexp.MustCompile(`(?i)\bcis\b`)
+)
+
+// CISScheme identifies the CIS Controls, such as 4 and its safeguard 4.1
+type CISScheme struct{}
+
+// Name gives the name of the scheme
+func (CISScheme) Name() string { return CIS }
+
+// Matches tells whether id is a control or safeguard
+func (CISScheme) Matches(id string) bool { return cisID.MatchString(strings.TrimSpace(id)) }
+
+// Control gives the control of a control or safeguard
+func (CISScheme) Control(id string) string {
+	return strings.SplitN(strings.TrimSpace(id), ".", 2)[0]
+}
+
+// IsSubcontrol tells whether id refers to a safeguard
+func (CISScheme) IsSubcontrol(id string) bool { return strings.Contains(id, ".") }
+
+// Normalize trims the id
+func (CISScheme) Normalize(id string) string { return strings.TrimSpace(id) }
+
+// Detect scores the control and safeguard ids of a catalog
+func (s CISScheme) Detect(c *catalog.Catalog) int {
+	return titleBonus(c, countIDs(c, s.Matches), cisTitle)
+}
+
+var (
+	pciID    = regexp.MustCompile(`^\d+(\.\d+){1,3}(\.[a-z])?$`)
+	pciTitle = regexp.MustCompile(`(?i)\bpci\b`)
+)
+
+// PCIDSSScheme identifies PCI DSS requirements, such as 8.3 and its sub-requirement 8.3.1.
+// Deeper requirements such as 8.3.1.a belong to the same sub-requirement.
+type PCIDSSScheme struct{}
+
+// Name gives the name of the scheme
+func (PCIDSSScheme) Name() string { return PCIDSS }
+
+// Matches tells whether id is a requirement
+func (PCIDSSScheme) Matches(id string) bool {
+	return pciID.MatchString(strings.ToLower(strings.TrimSpace(id)))
+}
+
+// Control gives the two first levels of a requirement
+func (PCIDSSScheme) Control(id string) string {
+	levels := strings.Split(strings.ToLower(strings.TrimSpace(id)), ".")
+	if len(levels) > 2 {
+		levels = levels[:2]
+	}
+	return strings.Join(levels, ".")
+}
+
+// IsSubcontrol tells whether id is deeper than the two first levels
+func (PCIDSSScheme) IsSubcontrol(id string) bool { return strings.Count(id, ".") > 1 }
+
+// Normalize gives the id lower cased
+func (PCIDSSScheme) Normalize(id string) string { return strings.ToLower(strings.TrimSpace(id)) }
+
+// Detect scores the requirement ids of a catalog
+func (s PCIDSSScheme) Detect(c *catalog.Catalog) int {
+	return titleBonus(c, countIDs(c, s.Matches), pciTitle)
+}
+
+// SchemeCatalog is a catalog whose control ids follow a scheme
+type SchemeCatalog struct {
+	ID     string
+	Scheme Scheme
+}
+
+// GetID returns the catalog ID
+func (s *SchemeCatalog) GetID() string {
+	return s.ID
+}
+
+// GetControl returns the control of a control or subcontrol id
+func (s *SchemeCatalog) GetControl(p string) string {
+	return s.Scheme.Control(p)
+}
+
+func (s *SchemeCatalog) isSubControl(id string) bool {
+	return s.Scheme.IsSubcontrol(id)
+}
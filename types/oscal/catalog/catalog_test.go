@@ -1,8 +1,13 @@
 package catalog
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"strings"
 	"testing"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestModifyParts(t *testing.T) {
@@ -71,3 +76,50 @@ func TestModifyParts(t *testing.T) {
 		t.Error("part not modified")
 	}
 }
+
+func TestProseFromJSONAndYAMLToXML(t *testing.T) {
+	var fromJSON Part
+	if err := json.Unmarshal([]byte(`{"id":"ac-1_smt","prose":["<p>first</p>","<p>second</p>"]}`), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML Part
+	if err := yaml.Unmarshal([]byte("id: ac-1_smt\nprose:\n- <p>first</p>\n- <p>second</p>\n"), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	for format, part := range map[string]*Part{"JSON": &fromJSON, "YAML": &fromYAML} {
+		b, err := xml.Marshal(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "<p>first</p><p>second</p>") {
+			t.Errorf("every block of prose read from %s should be written to XML, got %s", format, b)
+		}
+	}
+}
+
+func TestProseMarshalIsRepeatable(t *testing.T) {
+	var fromXML Part
+	if err := xml.Unmarshal([]byte(`<part id="ac-1_smt"><p>first</p><ul><li>second</li></ul></part>`), &fromXML); err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Part
+	if err := json.Unmarshal([]byte(`{"id":"ac-1_smt","prose":["<p>first</p>"]}`), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	built := NewPart("ac-1_smt", "", "first")
+	for _, part := range []*Part{&fromXML, &fromJSON, &built} {
+		for _, marshal := range []func(interface{}) ([]byte, error){json.Marshal, xml.Marshal} {
+			once, err := marshal(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			twice, err := marshal(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(once) != string(twice) {
+				t.Errorf("writing prose again should give the same, got\n%s\nthen\n%s", once, twice)
+			}
+		}
+	}
+}
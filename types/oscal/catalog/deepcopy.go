@@ -0,0 +1,1189 @@
+// Code generated by go generate; DO NOT EDIT.
+package catalog
+
+// DeepCopy copies the Assign, sharing no memory with it
+func (in *Assign) DeepCopy() *Assign {
+	if in == nil {
+		return nil
+	}
+	out := new(Assign)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Assign into out
+func (in *Assign) DeepCopyInto(out *Assign) {
+	*out = *in
+}
+
+// DeepCopy copies the B, sharing no memory with it
+func (in *B) DeepCopy() *B {
+	if in == nil {
+		return nil
+	}
+	out := new(B)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the B into out
+func (in *B) DeepCopyInto(out *B) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+}
+
+// DeepCopy copies the Catalog, sharing no memory with it
+func (in *Catalog) DeepCopy() *Catalog {
+	if in == nil {
+		return nil
+	}
+	out := new(Catalog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Catalog into out
+func (in *Catalog) DeepCopyInto(out *Catalog) {
+	*out = *in
+	out.Declarations = in.Declarations.DeepCopy()
+	out.References = in.References.DeepCopy()
+	if in.Sections != nil {
+		out.Sections = make([]Section, len(in.Sections))
+		for i := range in.Sections {
+			in.Sections[i].DeepCopyInto(&out.Sections[i])
+		}
+	}
+	if in.Groups != nil {
+		out.Groups = make([]Group, len(in.Groups))
+		for i := range in.Groups {
+			in.Groups[i].DeepCopyInto(&out.Groups[i])
+		}
+	}
+	if in.Controls != nil {
+		out.Controls = make([]Control, len(in.Controls))
+		for i := range in.Controls {
+			in.Controls[i].DeepCopyInto(&out.Controls[i])
+		}
+	}
+}
+
+// DeepCopy copies the Citation, sharing no memory with it
+func (in *Citation) DeepCopy() *Citation {
+	if in == nil {
+		return nil
+	}
+	out := new(Citation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Citation into out
+func (in *Citation) DeepCopyInto(out *Citation) {
+	*out = *in
+	in.Href.DeepCopyInto(&out.Href)
+}
+
+// DeepCopy copies the Code, sharing no memory with it
+func (in *Code) DeepCopy() *Code {
+	if in == nil {
+		return nil
+	}
+	out := new(Code)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Code into out
+func (in *Code) DeepCopyInto(out *Code) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+}
+
+// DeepCopy copies the Constraint, sharing no memory with it
+func (in *Constraint) DeepCopy() *Constraint {
+	if in == nil {
+		return nil
+	}
+	out := new(Constraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Constraint into out
+func (in *Constraint) DeepCopyInto(out *Constraint) {
+	*out = *in
+}
+
+// DeepCopy copies the Control, sharing no memory with it
+func (in *Control) DeepCopy() *Control {
+	if in == nil {
+		return nil
+	}
+	out := new(Control)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Control into out
+func (in *Control) DeepCopyInto(out *Control) {
+	*out = *in
+	if in.Props != nil {
+		out.Props = make([]Prop, len(in.Props))
+		for i := range in.Props {
+			in.Props[i].DeepCopyInto(&out.Props[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	out.References = in.References.DeepCopy()
+	if in.Params != nil {
+		out.Params = make([]Param, len(in.Params))
+		for i := range in.Params {
+			in.Params[i].DeepCopyInto(&out.Params[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+	if in.Subcontrols != nil {
+		out.Subcontrols = make([]Subcontrol, len(in.Subcontrols))
+		for i := range in.Subcontrols {
+			in.Subcontrols[i].DeepCopyInto(&out.Subcontrols[i])
+		}
+	}
+}
+
+// DeepCopy copies the Declarations, sharing no memory with it
+func (in *Declarations) DeepCopy() *Declarations {
+	if in == nil {
+		return nil
+	}
+	out := new(Declarations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Declarations into out
+func (in *Declarations) DeepCopyInto(out *Declarations) {
+	*out = *in
+	in.Href.DeepCopyInto(&out.Href)
+}
+
+// DeepCopy copies the Desc, sharing no memory with it
+func (in *Desc) DeepCopy() *Desc {
+	if in == nil {
+		return nil
+	}
+	out := new(Desc)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Desc into out
+func (in *Desc) DeepCopyInto(out *Desc) {
+	*out = *in
+}
+
+// DeepCopy copies the EM, sharing no memory with it
+func (in *EM) DeepCopy() *EM {
+	if in == nil {
+		return nil
+	}
+	out := new(EM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the EM into out
+func (in *EM) DeepCopyInto(out *EM) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+}
+
+// DeepCopy copies the Group, sharing no memory with it
+func (in *Group) DeepCopy() *Group {
+	if in == nil {
+		return nil
+	}
+	out := new(Group)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Group into out
+func (in *Group) DeepCopyInto(out *Group) {
+	*out = *in
+	if in.Props != nil {
+		out.Props = make([]Prop, len(in.Props))
+		for i := range in.Props {
+			in.Props[i].DeepCopyInto(&out.Props[i])
+		}
+	}
+	out.References = in.References.DeepCopy()
+	if in.Params != nil {
+		out.Params = make([]Param, len(in.Params))
+		for i := range in.Params {
+			in.Params[i].DeepCopyInto(&out.Params[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+	if in.Groups != nil {
+		out.Groups = make([]Group, len(in.Groups))
+		for i := range in.Groups {
+			in.Groups[i].DeepCopyInto(&out.Groups[i])
+		}
+	}
+	if in.Controls != nil {
+		out.Controls = make([]Control, len(in.Controls))
+		for i := range in.Controls {
+			in.Controls[i].DeepCopyInto(&out.Controls[i])
+		}
+	}
+}
+
+// DeepCopy copies the Guideline, sharing no memory with it
+func (in *Guideline) DeepCopy() *Guideline {
+	if in == nil {
+		return nil
+	}
+	out := new(Guideline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Guideline into out
+func (in *Guideline) DeepCopyInto(out *Guideline) {
+	*out = *in
+	out.Prose = in.Prose.DeepCopy()
+}
+
+// DeepCopy copies the Href, sharing no memory with it
+func (in *Href) DeepCopy() *Href {
+	if in == nil {
+		return nil
+	}
+	out := new(Href)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Href into out
+func (in *Href) DeepCopyInto(out *Href) {
+	*out = *in
+	if in.URL != nil {
+		v := *in.URL
+		out.URL = &v
+	}
+}
+
+// DeepCopy copies the I, sharing no memory with it
+func (in *I) DeepCopy() *I {
+	if in == nil {
+		return nil
+	}
+	out := new(I)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the I into out
+func (in *I) DeepCopyInto(out *I) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+}
+
+// DeepCopy copies the Link, sharing no memory with it
+func (in *Link) DeepCopy() *Link {
+	if in == nil {
+		return nil
+	}
+	out := new(Link)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Link into out
+func (in *Link) DeepCopyInto(out *Link) {
+	*out = *in
+	in.Href.DeepCopyInto(&out.Href)
+}
+
+// DeepCopy copies the OL, sharing no memory with it
+func (in *OL) DeepCopy() *OL {
+	if in == nil {
+		return nil
+	}
+	out := new(OL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the OL into out
+func (in *OL) DeepCopyInto(out *OL) {
+	*out = *in
+}
+
+// DeepCopy copies the P, sharing no memory with it
+func (in *P) DeepCopy() *P {
+	if in == nil {
+		return nil
+	}
+	out := new(P)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the P into out
+func (in *P) DeepCopyInto(out *P) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+	if in.Assignments != nil {
+		out.Assignments = make([]Assign, len(in.Assignments))
+		for i := range in.Assignments {
+			in.Assignments[i].DeepCopyInto(&out.Assignments[i])
+		}
+	}
+	if in.Selection != nil {
+		out.Selection = make([]Select, len(in.Selection))
+		for i := range in.Selection {
+			in.Selection[i].DeepCopyInto(&out.Selection[i])
+		}
+	}
+}
+
+// DeepCopy copies the Param, sharing no memory with it
+func (in *Param) DeepCopy() *Param {
+	if in == nil {
+		return nil
+	}
+	out := new(Param)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Param into out
+func (in *Param) DeepCopyInto(out *Param) {
+	*out = *in
+	if in.Descriptions != nil {
+		out.Descriptions = make([]Desc, len(in.Descriptions))
+		for i := range in.Descriptions {
+			in.Descriptions[i].DeepCopyInto(&out.Descriptions[i])
+		}
+	}
+	if in.Constraints != nil {
+		out.Constraints = make([]Constraint, len(in.Constraints))
+		for i := range in.Constraints {
+			in.Constraints[i].DeepCopyInto(&out.Constraints[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	if in.Guidance != nil {
+		out.Guidance = make([]Guideline, len(in.Guidance))
+		for i := range in.Guidance {
+			in.Guidance[i].DeepCopyInto(&out.Guidance[i])
+		}
+	}
+	out.Select = in.Select.DeepCopy()
+}
+
+// DeepCopy copies the Part, sharing no memory with it
+func (in *Part) DeepCopy() *Part {
+	if in == nil {
+		return nil
+	}
+	out := new(Part)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Part into out
+func (in *Part) DeepCopyInto(out *Part) {
+	*out = *in
+	if in.Props != nil {
+		out.Props = make([]Prop, len(in.Props))
+		for i := range in.Props {
+			in.Props[i].DeepCopyInto(&out.Props[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+	out.Prose = in.Prose.DeepCopy()
+}
+
+// DeepCopy copies the Pre, sharing no memory with it
+func (in *Pre) DeepCopy() *Pre {
+	if in == nil {
+		return nil
+	}
+	out := new(Pre)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Pre into out
+func (in *Pre) DeepCopyInto(out *Pre) {
+	*out = *in
+}
+
+// DeepCopy copies the Prop, sharing no memory with it
+func (in *Prop) DeepCopy() *Prop {
+	if in == nil {
+		return nil
+	}
+	out := new(Prop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Prop into out
+func (in *Prop) DeepCopyInto(out *Prop) {
+	*out = *in
+}
+
+// DeepCopy copies the Prose, sharing no memory with it
+func (in *Prose) DeepCopy() *Prose {
+	if in == nil {
+		return nil
+	}
+	out := new(Prose)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Prose into out
+func (in *Prose) DeepCopyInto(out *Prose) {
+	*out = *in
+	if in.raw != nil {
+		out.raw = make([]string, len(in.raw))
+		copy(out.raw, in.raw)
+	}
+	if in.order != nil {
+		out.order = make([]string, len(in.order))
+		copy(out.order, in.order)
+	}
+	if in.P != nil {
+		out.P = make([]P, len(in.P))
+		for i := range in.P {
+			in.P[i].DeepCopyInto(&out.P[i])
+		}
+	}
+	if in.UL != nil {
+		out.UL = make([]UL, len(in.UL))
+		for i := range in.UL {
+			in.UL[i].DeepCopyInto(&out.UL[i])
+		}
+	}
+	if in.OL != nil {
+		out.OL = make([]OL, len(in.OL))
+		for i := range in.OL {
+			in.OL[i].DeepCopyInto(&out.OL[i])
+		}
+	}
+	if in.Pre != nil {
+		out.Pre = make([]Pre, len(in.Pre))
+		for i := range in.Pre {
+			in.Pre[i].DeepCopyInto(&out.Pre[i])
+		}
+	}
+}
+
+// DeepCopy copies the Q, sharing no memory with it
+func (in *Q) DeepCopy() *Q {
+	if in == nil {
+		return nil
+	}
+	out := new(Q)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Q into out
+func (in *Q) DeepCopyInto(out *Q) {
+	*out = *in
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+}
+
+// DeepCopy copies the Raw, sharing no memory with it
+func (in *Raw) DeepCopy() *Raw {
+	if in == nil {
+		return nil
+	}
+	out := new(Raw)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Raw into out
+func (in *Raw) DeepCopyInto(out *Raw) {
+	*out = *in
+}
+
+// DeepCopy copies the Ref, sharing no memory with it
+func (in *Ref) DeepCopy() *Ref {
+	if in == nil {
+		return nil
+	}
+	out := new(Ref)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Ref into out
+func (in *Ref) DeepCopyInto(out *Ref) {
+	*out = *in
+	if in.Citations != nil {
+		out.Citations = make([]Citation, len(in.Citations))
+		for i := range in.Citations {
+			in.Citations[i].DeepCopyInto(&out.Citations[i])
+		}
+	}
+	out.Prose = in.Prose.DeepCopy()
+}
+
+// DeepCopy copies the References, sharing no memory with it
+func (in *References) DeepCopy() *References {
+	if in == nil {
+		return nil
+	}
+	out := new(References)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the References into out
+func (in *References) DeepCopyInto(out *References) {
+	*out = *in
+	if in.Links != nil {
+		out.Links = make([]Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	if in.Refs != nil {
+		out.Refs = make([]Ref, len(in.Refs))
+		for i := range in.Refs {
+			in.Refs[i].DeepCopyInto(&out.Refs[i])
+		}
+	}
+}
+
+// DeepCopy copies the Section, sharing no memory with it
+func (in *Section) DeepCopy() *Section {
+	if in == nil {
+		return nil
+	}
+	out := new(Section)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Section into out
+func (in *Section) DeepCopyInto(out *Section) {
+	*out = *in
+	out.References = in.References.DeepCopy()
+	if in.Sections != nil {
+		out.Sections = make([]Section, len(in.Sections))
+		for i := range in.Sections {
+			in.Sections[i].DeepCopyInto(&out.Sections[i])
+		}
+	}
+	out.Prose = in.Prose.DeepCopy()
+}
+
+// DeepCopy copies the Select, sharing no memory with it
+func (in *Select) DeepCopy() *Select {
+	if in == nil {
+		return nil
+	}
+	out := new(Select)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Select into out
+func (in *Select) DeepCopyInto(out *Select) {
+	*out = *in
+	if in.Alternatives != nil {
+		out.Alternatives = make([]Choice, len(in.Alternatives))
+		copy(out.Alternatives, in.Alternatives)
+	}
+}
+
+// DeepCopy copies the Span, sharing no memory with it
+func (in *Span) DeepCopy() *Span {
+	if in == nil {
+		return nil
+	}
+	out := new(Span)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Span into out
+func (in *Span) DeepCopyInto(out *Span) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+}
+
+// DeepCopy copies the Strong, sharing no memory with it
+func (in *Strong) DeepCopy() *Strong {
+	if in == nil {
+		return nil
+	}
+	out := new(Strong)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Strong into out
+func (in *Strong) DeepCopyInto(out *Strong) {
+	*out = *in
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]EM, len(in.EM))
+		for i := range in.EM {
+			in.EM[i].DeepCopyInto(&out.EM[i])
+		}
+	}
+	if in.Strong != nil {
+		out.Strong = make([]Strong, len(in.Strong))
+		for i := range in.Strong {
+			in.Strong[i].DeepCopyInto(&out.Strong[i])
+		}
+	}
+	if in.B != nil {
+		out.B = make([]B, len(in.B))
+		for i := range in.B {
+			in.B[i].DeepCopyInto(&out.B[i])
+		}
+	}
+	if in.I != nil {
+		out.I = make([]I, len(in.I))
+		for i := range in.I {
+			in.I[i].DeepCopyInto(&out.I[i])
+		}
+	}
+	if in.Sub != nil {
+		out.Sub = make([]Sub, len(in.Sub))
+		for i := range in.Sub {
+			in.Sub[i].DeepCopyInto(&out.Sub[i])
+		}
+	}
+	if in.Sup != nil {
+		out.Sup = make([]Sup, len(in.Sup))
+		for i := range in.Sup {
+			in.Sup[i].DeepCopyInto(&out.Sup[i])
+		}
+	}
+	if in.Xref != nil {
+		out.Xref = make([]Xref, len(in.Xref))
+		for i := range in.Xref {
+			in.Xref[i].DeepCopyInto(&out.Xref[i])
+		}
+	}
+}
+
+// DeepCopy copies the Sub, sharing no memory with it
+func (in *Sub) DeepCopy() *Sub {
+	if in == nil {
+		return nil
+	}
+	out := new(Sub)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Sub into out
+func (in *Sub) DeepCopyInto(out *Sub) {
+	*out = *in
+}
+
+// DeepCopy copies the Subcontrol, sharing no memory with it
+func (in *Subcontrol) DeepCopy() *Subcontrol {
+	if in == nil {
+		return nil
+	}
+	out := new(Subcontrol)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Subcontrol into out
+func (in *Subcontrol) DeepCopyInto(out *Subcontrol) {
+	*out = *in
+	if in.Props != nil {
+		out.Props = make([]Prop, len(in.Props))
+		for i := range in.Props {
+			in.Props[i].DeepCopyInto(&out.Props[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	out.References = in.References.DeepCopy()
+	if in.Params != nil {
+		out.Params = make([]Param, len(in.Params))
+		for i := range in.Params {
+			in.Params[i].DeepCopyInto(&out.Params[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+}
+
+// DeepCopy copies the Sup, sharing no memory with it
+func (in *Sup) DeepCopy() *Sup {
+	if in == nil {
+		return nil
+	}
+	out := new(Sup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Sup into out
+func (in *Sup) DeepCopyInto(out *Sup) {
+	*out = *in
+}
+
+// DeepCopy copies the UL, sharing no memory with it
+func (in *UL) DeepCopy() *UL {
+	if in == nil {
+		return nil
+	}
+	out := new(UL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the UL into out
+func (in *UL) DeepCopyInto(out *UL) {
+	*out = *in
+}
+
+// DeepCopy copies the Xref, sharing no memory with it
+func (in *Xref) DeepCopy() *Xref {
+	if in == nil {
+		return nil
+	}
+	out := new(Xref)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Xref into out
+func (in *Xref) DeepCopyInto(out *Xref) {
+	*out = *in
+	out.Href = in.Href.DeepCopy()
+	if in.Q != nil {
+		out.Q = make([]Q, len(in.Q))
+		for i := range in.Q {
+			in.Q[i].DeepCopyInto(&out.Q[i])
+		}
+	}
+	if in.Code != nil {
+		out.Code = make([]Code, len(in.Code))
+		for i := range in.Code {
+			in.Code[i].DeepCopyInto(&out.Code[i])
+		}
+	}
+	if in.EM != nil {
+		out.EM = make([]struct {
+			OptionalClass string `xml:"class,attr"`
+			Value         string `xml:",chardata"`
+		}, len(in.EM))
+		copy(out.EM, in.EM)
+	}
+}
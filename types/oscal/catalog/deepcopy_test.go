@@ -0,0 +1,44 @@
+package catalog
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	raw, err := ioutil.ReadFile("../../../test_util/artifacts/NIST_SP-800-53_rev4_catalog.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Catalog
+	if err := xml.Unmarshal(raw, &c); err != nil {
+		t.Fatal(err)
+	}
+	copied := c.DeepCopy()
+	if !reflect.DeepEqual(copied, &c) {
+		t.Fatal("a copy should equal the catalog copied")
+	}
+
+	ctrl := &copied.Groups[0].Controls[0]
+	ctrl.Parts[0].Parts[0].ModifyProse("ac-1_prm_1", "[CHANGED]")
+	ctrl.Params[0].Constraints = append(ctrl.Params[0].Constraints, Constraint{Value: "annually"})
+	ctrl.Subcontrols = append(ctrl.Subcontrols, Subcontrol{Id: "ac-1.1"})
+	copied.Groups[0].Controls[2].Subcontrols[0].Links[0].Href.URL.Fragment = "changed"
+	if text := copied.Groups[0].Controls[0].Parts[0].Parts[0].Prose.Text(); text == c.Groups[0].Controls[0].Parts[0].Parts[0].Prose.Text() {
+		t.Errorf("prose of the copy should change, got %q", text)
+	}
+	var original Catalog
+	if err := xml.Unmarshal(raw, &original); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&c, &original) {
+		t.Error("changing a copy should leave the catalog copied unchanged")
+	}
+
+	var nilCatalog *Catalog
+	if nilCatalog.DeepCopy() != nil {
+		t.Error("a nil catalog should be copied as nil")
+	}
+}
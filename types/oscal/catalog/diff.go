@@ -0,0 +1,402 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Kinds of changes of parts and parameters
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Word diff operations
+const (
+	WordEqual  = "equal"
+	WordInsert = "insert"
+	WordDelete = "delete"
+)
+
+// CatalogDiff reports the changes from an old to a new revision of a catalog, matching controls
+// and subcontrols by id
+type CatalogDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Added and Removed are control and subcontrol ids, Added in the order of the new catalog
+	// and the others in the order of the old one
+	Added     []string        `json:"added,omitempty"`
+	Removed   []string        `json:"removed,omitempty"`
+	Withdrawn []Withdrawal    `json:"withdrawn,omitempty"`
+	Changed   []ControlChange `json:"changed,omitempty"`
+}
+
+// Withdrawal is a control or subcontrol withdrawn by the new catalog
+type Withdrawal struct {
+	ID string `json:"id"`
+	// IncorporatedInto are the controls taking over the withdrawn one
+	IncorporatedInto []string `json:"incorporatedInto,omitempty"`
+}
+
+// ControlChange reports how a control or subcontrol of both catalogs changed
+type ControlChange struct {
+	ID     string        `json:"id"`
+	Title  *TextChange   `json:"title,omitempty"`
+	Parts  []PartChange  `json:"parts,omitempty"`
+	Params []ParamChange `json:"params,omitempty"`
+}
+
+// TextChange is a text of the old catalog replaced in the new one
+type TextChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// PartChange reports a part added, removed or whose prose changed, word by word
+type PartChange struct {
+	// ID is the id of the part, or its class and position when it has none
+	ID     string     `json:"id"`
+	Change string     `json:"change"`
+	Words  []WordDiff `json:"words"`
+}
+
+// WordDiff is a run of words kept, inserted or deleted
+type WordDiff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// ParamChange reports a parameter added, removed or changed. Old and New describe the label,
+// value, constraints and choices of the parameter.
+type ParamChange struct {
+	ID     string `json:"id"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// diffItem is a control or subcontrol of a catalog being compared
+type diffItem struct {
+	id     string
+	title  string
+	props  []Prop
+	links  []Link
+	params []Param
+	parts  []Part
+}
+
+func diffItems(c *Catalog) []diffItem {
+	var items []diffItem
+	Walk(c, VisitorFunc(func(n Node, _ []Node) error {
+		switch {
+		case n.Control != nil:
+			ctrl := n.Control
+			items = append(items, diffItem{id: ctrl.Id, title: string(ctrl.Title), props: ctrl.Props, links: ctrl.Links, params: ctrl.Params, parts: ctrl.Parts})
+		case n.Subcontrol != nil:
+			sc := n.Subcontrol
+			items = append(items, diffItem{id: sc.Id, title: string(sc.Title), props: sc.Props, links: sc.Links, params: sc.Params, parts: sc.Parts})
+		case n.Part != nil:
+			return SkipChildren
+		}
+		return nil
+	}))
+	return items
+}
+
+// withdrawn tells whether a control has the status Withdrawn
+func (item diffItem) withdrawn() bool {
+	for _, p := range item.props {
+		if p.Class == "status" && strings.EqualFold(strings.TrimSpace(p.Value), "withdrawn") {
+			return true
+		}
+	}
+	return false
+}
+
+func (item diffItem) withdrawal() Withdrawal {
+	w := Withdrawal{ID: item.id}
+	for _, l := range item.links {
+		if l.Rel != "incorporated-into" {
+			continue
+		}
+		into := strings.TrimSpace(l.Value)
+		if into == "" && l.Href.URL != nil {
+			into = l.Href.Fragment
+		}
+		w.IncorporatedInto = append(w.IncorporatedInto, into)
+	}
+	return w
+}
+
+func catalogName(c *Catalog) string {
+	if c.Title != "" {
+		return string(c.Title)
+	}
+	return c.Id
+}
+
+// Diff compares two revisions of a catalog. Controls and subcontrols are added or removed when
+// their id is in only one of the catalogs, and withdrawn when the new catalog gives them the
+// status Withdrawn. Controls of both catalogs change by their title, the prose of their parts,
+// compared word by word, and their parameters.
+func Diff(from, to *Catalog) *CatalogDiff {
+	d := &CatalogDiff{Old: catalogName(from), New: catalogName(to)}
+	oldItems, newItems := diffItems(from), diffItems(to)
+	inNew := make(map[string]diffItem, len(newItems))
+	for _, item := range newItems {
+		inNew[strings.ToLower(item.id)] = item
+	}
+	inOld := make(map[string]bool, len(oldItems))
+	for _, o := range oldItems {
+		key := strings.ToLower(o.id)
+		inOld[key] = true
+		n, ok := inNew[key]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, o.id)
+		case n.withdrawn() && !o.withdrawn():
+			d.Withdrawn = append(d.Withdrawn, n.withdrawal())
+		case !n.withdrawn():
+			if change, changed := diffControl(o, n); changed {
+				d.Changed = append(d.Changed, change)
+			}
+		}
+	}
+	for _, n := range newItems {
+		if inOld[strings.ToLower(n.id)] {
+			continue
+		}
+		if n.withdrawn() {
+			d.Withdrawn = append(d.Withdrawn, n.withdrawal())
+			continue
+		}
+		d.Added = append(d.Added, n.id)
+	}
+	return d
+}
+
+func diffControl(o, n diffItem) (ControlChange, bool) {
+	change := ControlChange{ID: n.id}
+	if strings.TrimSpace(o.title) != strings.TrimSpace(n.title) {
+		change.Title = &TextChange{Old: o.title, New: n.title}
+	}
+	change.Parts = diffParts(o.parts, n.parts)
+	change.Params = diffParams(o.params, n.params)
+	return change, change.Title != nil || len(change.Parts) > 0 || len(change.Params) > 0
+}
+
+// partText is the text of a part of a control, the parts nested in it being compared on their own
+type partText struct {
+	id   string
+	text string
+}
+
+func partTexts(parts []Part) []partText {
+	var texts []partText
+	var inParts func(parts []Part, parent string)
+	inParts = func(parts []Part, parent string) {
+		classes := make(map[string]int)
+		for _, p := range parts {
+			id := p.Id
+			if id == "" {
+				classes[p.Class]++
+				id = fmt.Sprintf("%s%s[%d]", parent, p.Class, classes[p.Class])
+			}
+			text := p.Prose.Text()
+			if p.Title != "" {
+				text = strings.TrimSpace(string(p.Title) + " " + text)
+			}
+			texts = append(texts, partText{id: id, text: text})
+			inParts(p.Parts, id+"/")
+		}
+	}
+	inParts(parts, "")
+	return texts
+}
+
+func diffParts(from, to []Part) []PartChange {
+	var changes []PartChange
+	oldTexts, newTexts := partTexts(from), partTexts(to)
+	inNew := make(map[string]string, len(newTexts))
+	for _, p := range newTexts {
+		inNew[p.id] = p.text
+	}
+	inOld := make(map[string]bool, len(oldTexts))
+	for _, p := range oldTexts {
+		inOld[p.id] = true
+		text, ok := inNew[p.id]
+		switch {
+		case !ok:
+			changes = append(changes, PartChange{ID: p.id, Change: Removed, Words: DiffWords(p.text, "")})
+		case text != p.text:
+			changes = append(changes, PartChange{ID: p.id, Change: Changed, Words: DiffWords(p.text, text)})
+		}
+	}
+	for _, p := range newTexts {
+		if !inOld[p.id] {
+			changes = append(changes, PartChange{ID: p.id, Change: Added, Words: DiffWords("", p.text)})
+		}
+	}
+	return changes
+}
+
+func diffParams(from, to []Param) []ParamChange {
+	var changes []ParamChange
+	inNew := make(map[string]Param, len(to))
+	for _, p := range to {
+		inNew[p.Id] = p
+	}
+	inOld := make(map[string]bool, len(from))
+	for _, p := range from {
+		inOld[p.Id] = true
+		n, ok := inNew[p.Id]
+		switch {
+		case !ok:
+			changes = append(changes, ParamChange{ID: p.Id, Change: Removed, Old: describeParam(p)})
+		case describeParam(p) != describeParam(n):
+			changes = append(changes, ParamChange{ID: p.Id, Change: Changed, Old: describeParam(p), New: describeParam(n)})
+		}
+	}
+	for _, p := range to {
+		if !inOld[p.Id] {
+			changes = append(changes, ParamChange{ID: p.Id, Change: Added, New: describeParam(p)})
+		}
+	}
+	return changes
+}
+
+func describeParam(p Param) string {
+	var items []string
+	if p.Label != "" {
+		items = append(items, fmt.Sprintf("label %q", strings.TrimSpace(string(p.Label))))
+	}
+	if p.Value != "" {
+		items = append(items, fmt.Sprintf("value %q", p.Value))
+	}
+	for _, c := range p.Constraints {
+		items = append(items, fmt.Sprintf("constraint %q", c.Value))
+	}
+	if p.Select != nil {
+		var choices []string
+		for _, c := range p.Select.Alternatives {
+			choices = append(choices, strings.TrimSpace(string(c)))
+		}
+		items = append(items, fmt.Sprintf("select %q", strings.Join(choices, " | ")))
+	}
+	return strings.Join(items, ", ")
+}
+
+// DiffWords compares two texts word by word, giving the runs of words kept, deleted from the
+// old text and inserted by the new one
+func DiffWords(from, to string) []WordDiff {
+	a, b := strings.Fields(from), strings.Fields(to)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diffs []WordDiff
+	add := func(op, word string) {
+		if last := len(diffs) - 1; last >= 0 && diffs[last].Op == op {
+			diffs[last].Text += " " + word
+			return
+		}
+		diffs = append(diffs, WordDiff{Op: op, Text: word})
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(WordEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(WordDelete, a[i])
+			i++
+		default:
+			add(WordInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(WordDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(WordInsert, b[j])
+	}
+	return diffs
+}
+
+// wordDiffText renders a word diff as text, deleted words as [-words-] and inserted ones as {+words+}
+func wordDiffText(words []WordDiff) string {
+	var runs []string
+	for _, w := range words {
+		switch w.Op {
+		case WordDelete:
+			runs = append(runs, "[-"+w.Text+"-]")
+		case WordInsert:
+			runs = append(runs, "{+"+w.Text+"+}")
+		default:
+			runs = append(runs, w.Text)
+		}
+	}
+	return strings.Join(runs, " ")
+}
+
+// WriteText writes the diff as a human readable report. Deleted words of changed prose are
+// shown as [-words-] and inserted ones as {+words+}.
+func (d *CatalogDiff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "old: %s\nnew: %s\n", d.Old, d.New)
+	fmt.Fprintf(&b, "\nadded (%d):\n", len(d.Added))
+	for _, id := range d.Added {
+		fmt.Fprintf(&b, "  %s\n", id)
+	}
+	fmt.Fprintf(&b, "\nremoved (%d):\n", len(d.Removed))
+	for _, id := range d.Removed {
+		fmt.Fprintf(&b, "  %s\n", id)
+	}
+	fmt.Fprintf(&b, "\nwithdrawn (%d):\n", len(d.Withdrawn))
+	for _, wd := range d.Withdrawn {
+		if len(wd.IncorporatedInto) == 0 {
+			fmt.Fprintf(&b, "  %s\n", wd.ID)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s, incorporated into %s\n", wd.ID, strings.Join(wd.IncorporatedInto, ", "))
+	}
+	fmt.Fprintf(&b, "\nchanged (%d):\n", len(d.Changed))
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "  %s\n", c.ID)
+		if c.Title != nil {
+			fmt.Fprintf(&b, "    title: %q -> %q\n", c.Title.Old, c.Title.New)
+		}
+		for _, p := range c.Parts {
+			fmt.Fprintf(&b, "    part %s %s: %s\n", p.ID, p.Change, wordDiffText(p.Words))
+		}
+		for _, p := range c.Params {
+			switch p.Change {
+			case Added:
+				fmt.Fprintf(&b, "    param %s added: %s\n", p.ID, p.New)
+			case Removed:
+				fmt.Fprintf(&b, "    param %s removed: %s\n", p.ID, p.Old)
+			default:
+				fmt.Fprintf(&b, "    param %s changed: %s -> %s\n", p.ID, p.Old, p.New)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
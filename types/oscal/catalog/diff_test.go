@@ -0,0 +1,208 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tt := []struct {
+		from, to string
+		expected []WordDiff
+	}{
+		{from: "", to: "", expected: nil},
+		{from: "a b c", to: "a b c", expected: []WordDiff{{WordEqual, "a b c"}}},
+		{from: "", to: "a b", expected: []WordDiff{{WordInsert, "a b"}}},
+		{from: "a b", to: "", expected: []WordDiff{{WordDelete, "a b"}}},
+		{
+			from: "The organization develops and documents an access control policy",
+			to:   "The organization develops, documents and disseminates an access control policy",
+			expected: []WordDiff{
+				{WordEqual, "The organization"},
+				{WordDelete, "develops and"},
+				{WordInsert, "develops,"},
+				{WordEqual, "documents"},
+				{WordInsert, "and disseminates"},
+				{WordEqual, "an access control policy"},
+			},
+		},
+	}
+	for _, tc := range tt {
+		if actual := DiffWords(tc.from, tc.to); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("diffing %q with %q: expected %v, got %v", tc.from, tc.to, tc.expected, actual)
+		}
+	}
+}
+
+func TestProseText(t *testing.T) {
+	var part Part
+	err := xml.Unmarshal([]byte(`<part id="ac-1_smt.a"><p>Develops &amp; documents <em>an</em> access control policy
+		to <insert param-id="ac-1_prm_1"/> personnel;</p><ul><li>one</li></ul></part>`), &part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Develops & documents an access control policy to {ac-1_prm_1} personnel; one"
+	if text := part.Prose.Text(); text != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+	if text := NewPart("p", "", "built <b>in code</b>").Prose.Text(); text != "built in code" {
+		t.Errorf("unexpected text %q of prose built in code", text)
+	}
+	var nilProse *Prose
+	if nilProse.Text() != "" {
+		t.Error("nil prose should have no text")
+	}
+}
+
+func diffTestCatalogs() (*Catalog, *Catalog) {
+	from := &Catalog{
+		Title: "Rev 4",
+		Groups: []Group{
+			Group{
+				Id: "ac",
+				Controls: []Control{
+					NewControl("ac-1", "Access Control Policy and Procedures", &ControlOpts{
+						Params: []Param{
+							Param{Id: "ac-1_prm_1", Label: "organization-defined personnel"},
+							Param{Id: "ac-1_prm_2", Label: "organization-defined frequency"},
+						},
+						Parts: []Part{NewPart("ac-1_smt.a", "", "The organization develops and documents a policy")},
+					}),
+					NewControl("ac-3", "Access Enforcement", &ControlOpts{
+						Subcontrols: []Subcontrol{
+							Subcontrol{Id: "ac-3.1", Title: "Restricted Access to Privileged Functions"},
+							Subcontrol{Id: "ac-3.2", Title: "Dual Authorization"},
+						},
+					}),
+					NewControl("ac-4", "Information Flow Enforcement", nil),
+				},
+			},
+		},
+	}
+	to := &Catalog{
+		Title: "Rev 5",
+		Groups: []Group{
+			Group{
+				Id: "ac",
+				Controls: []Control{
+					NewControl("ac-1", "Policy and Procedures", &ControlOpts{
+						Params: []Param{
+							Param{Id: "ac-1_prm_1", Label: "organization-defined personnel or roles"},
+							Param{Id: "ac-1_prm_3", Label: "organization-defined events"},
+						},
+						Parts: []Part{
+							NewPart("ac-1_smt.a", "", "The organization develops, documents and disseminates a policy"),
+							NewPart("ac-1_smt.b", "", "Designate an official"),
+						},
+					}),
+					NewControl("ac-3", "Access Enforcement", &ControlOpts{
+						Subcontrols: []Subcontrol{
+							Subcontrol{
+								Id:    "ac-3.1",
+								Title: "Restricted Access to Privileged Functions",
+								Props: []Prop{Prop{Class: "status", Value: "Withdrawn"}},
+								Links: []Link{Link{Rel: "incorporated-into", Value: "AC-6"}},
+							},
+						},
+					}),
+					NewControl("ac-4", "Information Flow Enforcement", nil),
+					NewControl("ac-25", "Reference Monitor", nil),
+				},
+			},
+		},
+	}
+	return from, to
+}
+
+func TestDiff(t *testing.T) {
+	d := Diff(diffTestCatalogs())
+	if d.Old != "Rev 4" || d.New != "Rev 5" {
+		t.Errorf("diff should name the catalogs by title, got %s and %s", d.Old, d.New)
+	}
+	if !reflect.DeepEqual(d.Added, []string{"ac-25"}) || !reflect.DeepEqual(d.Removed, []string{"ac-3.2"}) {
+		t.Errorf("expected ac-25 added and ac-3.2 removed, got %v and %v", d.Added, d.Removed)
+	}
+	expectedWithdrawn := []Withdrawal{{ID: "ac-3.1", IncorporatedInto: []string{"AC-6"}}}
+	if !reflect.DeepEqual(d.Withdrawn, expectedWithdrawn) {
+		t.Errorf("expected withdrawn %v, got %v", expectedWithdrawn, d.Withdrawn)
+	}
+	if len(d.Changed) != 1 {
+		t.Fatalf("only ac-1 should change, got %+v", d.Changed)
+	}
+	c := d.Changed[0]
+	if c.ID != "ac-1" || c.Title == nil || c.Title.New != "Policy and Procedures" {
+		t.Errorf("title of ac-1 should change, got %+v", c)
+	}
+	expectedParts := []PartChange{
+		{ID: "ac-1_smt.a", Change: Changed, Words: []WordDiff{
+			{WordEqual, "The organization"},
+			{WordDelete, "develops and"},
+			{WordInsert, "develops,"},
+			{WordEqual, "documents"},
+			{WordInsert, "and disseminates"},
+			{WordEqual, "a policy"},
+		}},
+		{ID: "ac-1_smt.b", Change: Added, Words: []WordDiff{{WordInsert, "Designate an official"}}},
+	}
+	if !reflect.DeepEqual(c.Parts, expectedParts) {
+		t.Errorf("expected part changes %+v, got %+v", expectedParts, c.Parts)
+	}
+	expectedParams := []ParamChange{
+		{ID: "ac-1_prm_1", Change: Changed, Old: `label "organization-defined personnel"`, New: `label "organization-defined personnel or roles"`},
+		{ID: "ac-1_prm_2", Change: Removed, Old: `label "organization-defined frequency"`},
+		{ID: "ac-1_prm_3", Change: Added, New: `label "organization-defined events"`},
+	}
+	if !reflect.DeepEqual(c.Params, expectedParams) {
+		t.Errorf("expected param changes %+v, got %+v", expectedParams, c.Params)
+	}
+
+	var b bytes.Buffer
+	if err := d.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"added (1):\n  ac-25\n",
+		"removed (1):\n  ac-3.2\n",
+		"withdrawn (1):\n  ac-3.1, incorporated into AC-6\n",
+		`    title: "Access Control Policy and Procedures" -> "Policy and Procedures"`,
+		"    part ac-1_smt.a changed: The organization [-develops and-] {+develops,+} documents {+and disseminates+} a policy\n",
+		`    param ac-1_prm_2 removed: label "organization-defined frequency"`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("text diff should contain %q, got\n%s", expected, b.String())
+		}
+	}
+}
+
+func TestDiffNISTCatalog(t *testing.T) {
+	raw, err := ioutil.ReadFile("../../../test_util/artifacts/NIST_SP-800-53_rev4_catalog.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var from, to Catalog
+	if err := xml.Unmarshal(raw, &from); err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(raw, &to); err != nil {
+		t.Fatal(err)
+	}
+	if d := Diff(&from, &to); len(d.Added)+len(d.Removed)+len(d.Withdrawn)+len(d.Changed) > 0 {
+		t.Fatalf("a catalog should not differ from itself, got %+v", d)
+	}
+
+	// withdrawn enhancements of the catalog are reported when withdrawn by the new revision only
+	to.Groups[0].Controls[0].Title = "Policy and Procedures"
+	from.Groups[0].Controls[2].Subcontrols[0].Props = nil
+	d := Diff(&from, &to)
+	if len(d.Changed) != 1 || d.Changed[0].ID != "ac-1" || d.Changed[0].Title == nil {
+		t.Errorf("expected the title of ac-1 to change, got %+v", d.Changed)
+	}
+	expected := []Withdrawal{{ID: "ac-3.1", IncorporatedInto: []string{"AC-6"}}}
+	if !reflect.DeepEqual(d.Withdrawn, expected) {
+		t.Errorf("expected withdrawn %v, got %v", expected, d.Withdrawn)
+	}
+}
@@ -1,5 +1,7 @@
 package catalog
 
+//go:generate go run ../gen_deepcopy.go -o deepcopy.go catalog.go prose.go href.go
+
 // ControlOpts to generate controls
 type ControlOpts struct {
 	Params      []Param
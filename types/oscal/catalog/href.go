@@ -50,3 +50,26 @@ func (h *Href) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
 
 	return xml.Attr{Name: name}, nil
 }
+
+// MarshalYAML writes an href as a string
+func (h Href) MarshalYAML() (interface{}, error) {
+	if h.URL != nil {
+		return h.String(), nil
+	}
+
+	return "", nil
+}
+
+// UnmarshalYAML reads an href from a string
+func (h *Href) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	url, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	h.URL = url
+	return nil
+}
@@ -0,0 +1,100 @@
+package catalog
+
+import "strings"
+
+// Index looks up the elements of a catalog by id, regardless of case. Elements are indexed
+// through pointers into the catalog, which must not have elements added or removed while the
+// index is in use. The first of several elements with the same id is indexed.
+type Index struct {
+	groups      map[string]*Group
+	controls    map[string]*Control
+	subcontrols map[string]*Subcontrol
+	params      map[string]*Param
+	parts       map[string]*Part
+	parents     map[string]Node
+	// controlList and subcontrolList keep the catalog order
+	controlList    []*Control
+	subcontrolList []*Subcontrol
+}
+
+// NewIndex indexes the elements of a catalog
+func NewIndex(c *Catalog) *Index {
+	index := &Index{
+		groups:      make(map[string]*Group),
+		controls:    make(map[string]*Control),
+		subcontrols: make(map[string]*Subcontrol),
+		params:      make(map[string]*Param),
+		parts:       make(map[string]*Part),
+		parents:     make(map[string]Node),
+	}
+	Walk(c, VisitorFunc(func(n Node, ancestors []Node) error {
+		key := strings.ToLower(n.ID())
+		if key == "" {
+			return nil
+		}
+		switch {
+		case n.Group != nil && index.groups[key] == nil:
+			index.groups[key] = n.Group
+		case n.Control != nil && index.controls[key] == nil:
+			index.controls[key] = n.Control
+			index.controlList = append(index.controlList, n.Control)
+		case n.Subcontrol != nil && index.subcontrols[key] == nil:
+			index.subcontrols[key] = n.Subcontrol
+			index.subcontrolList = append(index.subcontrolList, n.Subcontrol)
+		case n.Param != nil && index.params[key] == nil:
+			index.params[key] = n.Param
+		case n.Part != nil && index.parts[key] == nil:
+			index.parts[key] = n.Part
+		default:
+			return nil
+		}
+		if len(ancestors) > 0 {
+			index.parents[key] = ancestors[len(ancestors)-1]
+		}
+		return nil
+	}))
+	return index
+}
+
+// Group finds a group by id, nil when the catalog has none
+func (index *Index) Group(id string) *Group {
+	return index.groups[strings.ToLower(id)]
+}
+
+// Control finds a control by id, nil when the catalog has none
+func (index *Index) Control(id string) *Control {
+	return index.controls[strings.ToLower(id)]
+}
+
+// Subcontrol finds a subcontrol by id, nil when the catalog has none
+func (index *Index) Subcontrol(id string) *Subcontrol {
+	return index.subcontrols[strings.ToLower(id)]
+}
+
+// Param finds a parameter by id, nil when the catalog has none
+func (index *Index) Param(id string) *Param {
+	return index.params[strings.ToLower(id)]
+}
+
+// Part finds a part by id, nil when the catalog has none
+func (index *Index) Part(id string) *Part {
+	return index.parts[strings.ToLower(id)]
+}
+
+// Parent finds the element the element with the given id is nested in: the control of a
+// subcontrol, the group of a control, the control, subcontrol or part of a part and so on. It
+// is false for top-level elements and ids not in the catalog.
+func (index *Index) Parent(id string) (Node, bool) {
+	parent, ok := index.parents[strings.ToLower(id)]
+	return parent, ok
+}
+
+// Controls lists the controls of the catalog in catalog order
+func (index *Index) Controls() []*Control {
+	return index.controlList
+}
+
+// Subcontrols lists the subcontrols of the catalog in catalog order
+func (index *Index) Subcontrols() []*Subcontrol {
+	return index.subcontrolList
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"html"
+	"io"
 	"regexp"
 	"strings"
 )
@@ -22,7 +24,7 @@ type Prose struct {
 // ReplaceInsertParams replaces insert parameters
 func (p *Prose) ReplaceInsertParams(parameterID, parameterValue string) error {
 
-	rs := fmt.Sprintf(`<insert param-id="%s">`, parameterID)
+	rs := fmt.Sprintf(`<insert param-id="%s"\s*/?>(</insert>)?`, regexp.QuoteMeta(parameterID))
 	regex, err := regexp.Compile(rs)
 	if err != nil {
 		return err
@@ -43,14 +45,85 @@ func (p *Prose) ReplaceInsertParams(parameterID, parameterValue string) error {
 	return nil
 }
 
+var (
+	proseInsert = regexp.MustCompile(`<insert\s+param-id="([^"]*)"\s*/?>(</insert>)?`)
+	proseTag    = regexp.MustCompile(`<[^>]*>`)
+	proseSpace  = regexp.MustCompile(`\s+`)
+)
+
+// Text gives the prose as plain text: markup is removed, insert markers are rendered as
+// {param-id} and white space is collapsed
+func (p *Prose) Text() string {
+	if p == nil {
+		return ""
+	}
+	var blocks []string
+	switch {
+	case p.order != nil:
+		// prose read from XML, in document order
+		var ulIndex, olIndex, pIndex, preIndex int
+		for _, element := range p.order {
+			switch {
+			case element == "ul" && ulIndex < len(p.UL):
+				blocks = append(blocks, p.UL[ulIndex].Raw)
+				ulIndex++
+			case element == "ol" && olIndex < len(p.OL):
+				blocks = append(blocks, p.OL[olIndex].Raw)
+				olIndex++
+			case element == "p" && pIndex < len(p.P):
+				blocks = append(blocks, p.P[pIndex].Raw)
+				pIndex++
+			case element == "pre" && preIndex < len(p.Pre):
+				blocks = append(blocks, p.Pre[preIndex].Raw)
+				preIndex++
+			}
+		}
+	case len(p.P)+len(p.UL)+len(p.OL)+len(p.Pre) > 0:
+		for _, para := range p.P {
+			blocks = append(blocks, para.Raw)
+		}
+		for _, ul := range p.UL {
+			blocks = append(blocks, ul.Raw)
+		}
+		for _, ol := range p.OL {
+			blocks = append(blocks, ol.Raw)
+		}
+		for _, pre := range p.Pre {
+			blocks = append(blocks, pre.Raw)
+		}
+	default:
+		// prose read from JSON
+		blocks = p.raw
+	}
+	text := proseInsert.ReplaceAllString(strings.Join(blocks, " "), "{$1}")
+	text = proseTag.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(proseSpace.ReplaceAllString(text, " "))
+}
+
 // MarshalXML ...
 func (p *Prose) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	raw := strings.Join(p.raw, "")
 
+	// prose read from JSON is decoded aside, so that writing it again gives the same
 	if raw != "" {
-		if err := xml.Unmarshal([]byte(raw), &p); err != nil {
-			return err
+		decoded := &Prose{}
+		d := xml.NewDecoder(strings.NewReader(raw))
+		for {
+			token, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if block, ok := token.(xml.StartElement); ok {
+				if err := decoded.UnmarshalXML(d, block); err != nil {
+					return err
+				}
+			}
 		}
+		p = decoded
 	}
 
 	p.XMLName = xml.Name{Local: "ul"}
@@ -118,30 +191,42 @@ func (p *Prose) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 
 // MarshalJSON ...
 func (p *Prose) MarshalJSON() ([]byte, error) {
+	raw, err := p.rawBlocks()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// rawBlocks gives the blocks of the prose as raw markup, as written to JSON and YAML
+func (p *Prose) rawBlocks() ([]string, error) {
+	// raw blocks are gathered aside, so that writing the prose again gives the same
+	raw := append([]string{}, p.raw...)
+
 	// If prose originates from OpenControl
 	if p.order == nil {
 		for _, para := range p.P {
 			if para.Raw != "" {
-				p.raw = append(p.raw, para.Raw)
+				raw = append(raw, para.Raw)
 			}
 		}
 		for _, ul := range p.UL {
 			if ul.Raw != "" {
-				p.raw = append(p.raw, ul.Raw)
+				raw = append(raw, ul.Raw)
 			}
 		}
 		for _, ol := range p.OL {
 			if ol.Raw != "" {
-				p.raw = append(p.raw, ol.Raw)
+				raw = append(raw, ol.Raw)
 			}
 		}
 		for _, pre := range p.Pre {
 			if pre.Raw != "" {
-				p.raw = append(p.raw, pre.Raw)
+				raw = append(raw, pre.Raw)
 			}
 		}
 
-		return json.Marshal(p.raw)
+		return raw, nil
 	}
 
 	// If prose originates from XML
@@ -154,60 +239,65 @@ func (p *Prose) MarshalJSON() ([]byte, error) {
 		switch element {
 		case "ul":
 			if ulIndex < len(p.UL) {
-				raw, err := xml.Marshal(p.UL[ulIndex])
+				block, err := xml.Marshal(p.UL[ulIndex])
 				if err != nil {
 					return nil, err
 				}
 
-				p.raw = append(p.raw, formatRawProse(string(raw)))
+				raw = append(raw, formatRawProse(string(block)))
 
 				ulIndex++
 			}
 
 		case "ol":
 			if olIndex < len(p.OL) {
-				raw, err := xml.Marshal(p.OL[olIndex])
+				block, err := xml.Marshal(p.OL[olIndex])
 				if err != nil {
 					return nil, err
 				}
 
-				p.raw = append(p.raw, formatRawProse(string(raw)))
+				raw = append(raw, formatRawProse(string(block)))
 
 				olIndex++
 			}
 
 		case "p":
 			if pIndex < len(p.P) {
-				raw, err := xml.Marshal(p.P[pIndex])
+				block, err := xml.Marshal(p.P[pIndex])
 				if err != nil {
 					return nil, err
 				}
 
-				p.raw = append(p.raw, formatRawProse(string(raw)))
+				raw = append(raw, formatRawProse(string(block)))
 
 				pIndex++
 			}
 
 		case "pre":
 			if preIndex < len(p.Pre) {
-				raw, err := xml.Marshal(p.Pre[preIndex])
+				block, err := xml.Marshal(p.Pre[preIndex])
 				if err != nil {
 					return nil, err
 				}
 
-				p.raw = append(p.raw, formatRawProse(string(raw)))
+				raw = append(raw, formatRawProse(string(block)))
 
 				preIndex++
 			}
 		}
 	}
 
-	return json.Marshal(p.raw)
+	return raw, nil
 }
 
 // MarshalYAML ...
 func (p *Prose) MarshalYAML() (interface{}, error) {
-	return p.raw, nil
+	return p.rawBlocks()
+}
+
+// UnmarshalYAML ...
+func (p *Prose) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&p.raw)
 }
 
 // UnmarshalJSON ...
@@ -230,6 +320,11 @@ func (r *Raw) MarshalYAML() (interface{}, error) {
 	return r.Value, nil
 }
 
+// UnmarshalYAML ...
+func (r *Raw) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshal(&r.Value)
+}
+
 // UnmarshalJSON ...
 func (r *Raw) UnmarshalJSON(data []byte) error {
 	var raw string
@@ -0,0 +1,166 @@
+package catalog
+
+import "errors"
+
+// SkipChildren is returned by a Visitor to skip the elements nested in the visited one
+var SkipChildren = errors.New("skip children")
+
+// Node is an element of a catalog visited by Walk. Exactly one of its fields is set.
+type Node struct {
+	Group      *Group
+	Control    *Control
+	Subcontrol *Subcontrol
+	Param      *Param
+	Part       *Part
+}
+
+// ID gives the id of the element
+func (n Node) ID() string {
+	switch {
+	case n.Group != nil:
+		return n.Group.Id
+	case n.Control != nil:
+		return n.Control.Id
+	case n.Subcontrol != nil:
+		return n.Subcontrol.Id
+	case n.Param != nil:
+		return n.Param.Id
+	case n.Part != nil:
+		return n.Part.Id
+	}
+	return ""
+}
+
+// Visitor visits the elements of a catalog. Ancestors are the elements the visited one is nested
+// in, outermost first.
+type Visitor interface {
+	Visit(n Node, ancestors []Node) error
+}
+
+// VisitorFunc makes a Visitor of a function
+type VisitorFunc func(n Node, ancestors []Node) error
+
+// Visit calls f
+func (f VisitorFunc) Visit(n Node, ancestors []Node) error {
+	return f(n, ancestors)
+}
+
+// Walk visits the groups, controls, subcontrols, params and parts of a catalog in document
+// order, each element before the ones nested in it: the params of an element come first, then
+// its parts, controls or subcontrols and groups. Elements are visited through pointers into the
+// catalog, which the visitor may modify but not add elements to or remove elements from. Walk
+// stops at the first error of the visitor other than SkipChildren and returns it.
+func Walk(c *Catalog, v Visitor) error {
+	if err := walkControls(c.Controls, nil, v); err != nil {
+		return err
+	}
+	return walkGroups(c.Groups, nil, v)
+}
+
+// visit visits a node, telling whether to walk its children
+func visit(n Node, ancestors []Node, v Visitor) (bool, error) {
+	err := v.Visit(n, ancestors)
+	if err == SkipChildren {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// with gives the ancestors of the children of a node. The slice is never shared between
+// siblings, so visitors may keep it.
+func with(ancestors []Node, n Node) []Node {
+	return append(ancestors[:len(ancestors):len(ancestors)], n)
+}
+
+func walkGroups(groups []Group, ancestors []Node, v Visitor) error {
+	for i := range groups {
+		g := &groups[i]
+		n := Node{Group: g}
+		walk, err := visit(n, ancestors, v)
+		if err != nil {
+			return err
+		}
+		if !walk {
+			continue
+		}
+		children := with(ancestors, n)
+		if err := walkParams(g.Params, children, v); err != nil {
+			return err
+		}
+		if err := walkParts(g.Parts, children, v); err != nil {
+			return err
+		}
+		if err := walkControls(g.Controls, children, v); err != nil {
+			return err
+		}
+		if err := walkGroups(g.Groups, children, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkControls(controls []Control, ancestors []Node, v Visitor) error {
+	for i := range controls {
+		ctrl := &controls[i]
+		n := Node{Control: ctrl}
+		walk, err := visit(n, ancestors, v)
+		if err != nil {
+			return err
+		}
+		if !walk {
+			continue
+		}
+		children := with(ancestors, n)
+		if err := walkParams(ctrl.Params, children, v); err != nil {
+			return err
+		}
+		if err := walkParts(ctrl.Parts, children, v); err != nil {
+			return err
+		}
+		for j := range ctrl.Subcontrols {
+			sc := &ctrl.Subcontrols[j]
+			scNode := Node{Subcontrol: sc}
+			walk, err := visit(scNode, children, v)
+			if err != nil {
+				return err
+			}
+			if !walk {
+				continue
+			}
+			if err := walkParams(sc.Params, with(children, scNode), v); err != nil {
+				return err
+			}
+			if err := walkParts(sc.Parts, with(children, scNode), v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walkParams(params []Param, ancestors []Node, v Visitor) error {
+	for i := range params {
+		if _, err := visit(Node{Param: &params[i]}, ancestors, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkParts(parts []Part, ancestors []Node, v Visitor) error {
+	for i := range parts {
+		n := Node{Part: &parts[i]}
+		walk, err := visit(n, ancestors, v)
+		if err != nil {
+			return err
+		}
+		if !walk {
+			continue
+		}
+		if err := walkParts(parts[i].Parts, with(ancestors, n), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package catalog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func walkTestCatalog() *Catalog {
+	return &Catalog{
+		Controls: []Control{NewControl("top-1", "Top", nil)},
+		Groups: []Group{
+			Group{
+				Id:     "ac",
+				Params: []Param{Param{Id: "ac_prm"}},
+				Parts:  []Part{Part{Id: "ac_smt"}},
+				Controls: []Control{
+					NewControl("ac-1", "Policy", &ControlOpts{
+						Params: []Param{Param{Id: "ac-1_prm_1"}},
+						Parts: []Part{
+							Part{Id: "ac-1_smt", Parts: []Part{Part{Id: "ac-1_smt.a"}}},
+						},
+						Subcontrols: []Subcontrol{
+							Subcontrol{
+								Id:     "ac-1.1",
+								Params: []Param{Param{Id: "ac-1.1_prm_1"}},
+								Parts:  []Part{Part{Id: "ac-1.1_smt"}},
+							},
+						},
+					}),
+				},
+				Groups: []Group{
+					Group{Id: "ac-nested", Controls: []Control{NewControl("ac-2", "Account Management", nil)}},
+				},
+			},
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var visited []string
+	err := Walk(walkTestCatalog(), VisitorFunc(func(n Node, ancestors []Node) error {
+		var path []string
+		for _, a := range ancestors {
+			path = append(path, a.ID())
+		}
+		visited = append(visited, strings.Join(append(path, n.ID()), "/"))
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"top-1",
+		"ac",
+		"ac/ac_prm",
+		"ac/ac_smt",
+		"ac/ac-1",
+		"ac/ac-1/ac-1_prm_1",
+		"ac/ac-1/ac-1_smt",
+		"ac/ac-1/ac-1_smt/ac-1_smt.a",
+		"ac/ac-1/ac-1.1",
+		"ac/ac-1/ac-1.1/ac-1.1_prm_1",
+		"ac/ac-1/ac-1.1/ac-1.1_smt",
+		"ac/ac-nested",
+		"ac/ac-nested/ac-2",
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected to visit\n%v\ngot\n%v", expected, visited)
+	}
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	var visited []string
+	err := Walk(walkTestCatalog(), VisitorFunc(func(n Node, _ []Node) error {
+		visited = append(visited, n.ID())
+		if n.Control != nil {
+			return SkipChildren
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"top-1", "ac", "ac_prm", "ac_smt", "ac-1", "ac-nested", "ac-2"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected to visit %v when skipping the children of controls, got %v", expected, visited)
+	}
+
+	stop := errors.New("stop")
+	visited = nil
+	err = Walk(walkTestCatalog(), VisitorFunc(func(n Node, _ []Node) error {
+		visited = append(visited, n.ID())
+		if n.ID() == "ac_prm" {
+			return stop
+		}
+		return nil
+	}))
+	if err != stop {
+		t.Errorf("expected the error of the visitor, got %v", err)
+	}
+	if !reflect.DeepEqual(visited, []string{"top-1", "ac", "ac_prm"}) {
+		t.Errorf("walk should stop at the error, visited %v", visited)
+	}
+}
+
+func TestWalkModifies(t *testing.T) {
+	c := walkTestCatalog()
+	Walk(c, VisitorFunc(func(n Node, _ []Node) error {
+		if n.Param != nil {
+			n.Param.Value = "set"
+		}
+		return nil
+	}))
+	if c.Groups[0].Controls[0].Subcontrols[0].Params[0].Value != "set" {
+		t.Error("walk should visit the params of the catalog rather than copies")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	c := walkTestCatalog()
+	index := NewIndex(c)
+	if index.Control("AC-1") != &c.Groups[0].Controls[0] {
+		t.Error("controls should be looked up regardless of case")
+	}
+	if index.Group("ac-nested") != &c.Groups[0].Groups[0] {
+		t.Error("nested groups should be indexed")
+	}
+	if index.Subcontrol("ac-1.1") != &c.Groups[0].Controls[0].Subcontrols[0] {
+		t.Error("subcontrols should be indexed")
+	}
+	if index.Param("ac-1.1_prm_1") == nil || index.Part("ac-1_smt.a") == nil {
+		t.Error("params and nested parts should be indexed")
+	}
+	if index.Control("ac-1.1") != nil || index.Control("ac-99") != nil {
+		t.Error("only controls should be found as controls")
+	}
+
+	for id, expected := range map[string]string{"ac-1.1": "ac-1", "ac-1": "ac", "ac-2": "ac-nested", "ac-1_smt.a": "ac-1_smt", "ac_prm": "ac"} {
+		if parent, ok := index.Parent(id); !ok || parent.ID() != expected {
+			t.Errorf("expected %s nested in %s, got %s", id, expected, parent.ID())
+		}
+	}
+	if _, ok := index.Parent("top-1"); ok {
+		t.Error("top-level controls have no parent")
+	}
+
+	var ids []string
+	for _, ctrl := range index.Controls() {
+		ids = append(ids, ctrl.Id)
+	}
+	for _, sc := range index.Subcontrols() {
+		ids = append(ids, sc.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"top-1", "ac-1", "ac-2", "ac-1.1"}) {
+		t.Errorf("controls and subcontrols should be listed in catalog order, got %v", ids)
+	}
+}
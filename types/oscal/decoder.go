@@ -0,0 +1,367 @@
+package oscal
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var errMalformed = errors.New("Malformed OSCAL. Must be XML, JSON or YAML")
+
+// errClosed stops the streaming of controls once the decoder is closed
+var errClosed = errors.New("decoder closed")
+
+// Decoder reads an OSCAL document from a stream in a single pass, without holding the whole
+// document in memory. Its format is detected from the first non-whitespace byte: '<' for XML,
+// '{' for JSON and anything else for YAML. JSON is read in either JSON encoding, legacy or
+// official. YAML documents are read whole. A decoder reads one document, either with Decode or
+// with Controls.
+type Decoder struct {
+	r *bufio.Reader
+
+	controls  chan *catalog.Control
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewDecoder returns a decoder reading from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), done: make(chan struct{})}
+}
+
+// format peeks at the first non-whitespace byte of the document, leaving it unread
+func (d *Decoder) format() (byte, error) {
+	for {
+		b, err := d.r.Peek(1)
+		if err == io.EOF {
+			return 0, errMalformed
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			d.r.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// Decode decodes the catalog or profile of the document
+func (d *Decoder) Decode() (*OSCAL, error) {
+	format, err := d.format()
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case '<':
+		return d.decodeXML()
+	case '{':
+		return d.decodeJSON()
+	}
+	return d.decodeYAML()
+}
+
+func (d *Decoder) decodeXML() (*OSCAL, error) {
+	x := xml.NewDecoder(d.r)
+	for {
+		token, err := x.Token()
+		if err != nil {
+			return nil, errMalformed
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "catalog":
+			var catalog catalog.Catalog
+			if err := x.DecodeElement(&catalog, &start); err != nil {
+				return nil, err
+			}
+			return &OSCAL{Catalog: &catalog}, nil
+
+		case "profile":
+			var profile profile.Profile
+			if err := x.DecodeElement(&profile, &start); err != nil {
+				return nil, err
+			}
+			return &OSCAL{Profile: &profile}, nil
+		}
+	}
+}
+
+func (d *Decoder) decodeJSON() (*OSCAL, error) {
+	j := json.NewDecoder(d.r)
+	if err := expectDelim(j, '{'); err != nil {
+		return nil, errMalformed
+	}
+	for j.More() {
+		key, err := j.Token()
+		if err != nil {
+			return nil, errMalformed
+		}
+		switch key {
+		case "catalog":
+			var catalog catalog.Catalog
+			if err := decodeJSONValue(j, &catalog); err != nil {
+				return nil, err
+			}
+			return &OSCAL{Catalog: &catalog}, nil
+
+		case "profile":
+			var profile profile.Profile
+			if err := decodeJSONValue(j, &profile); err != nil {
+				return nil, err
+			}
+			return &OSCAL{Profile: &profile}, nil
+		}
+		if err := skipValue(j); err != nil {
+			return nil, errMalformed
+		}
+	}
+	return nil, errMalformed
+}
+
+func (d *Decoder) decodeYAML() (*OSCAL, error) {
+	var o OSCAL
+	if err := yaml.NewDecoder(d.r).Decode(&o); err != nil {
+		return nil, errMalformed
+	}
+	if o.Catalog == nil && o.Profile == nil {
+		return nil, errMalformed
+	}
+	return &o, nil
+}
+
+// Controls streams the controls of a catalog in document order, including those of nested
+// groups, decoding one control at a time. The controls of YAML catalogs come in catalog order,
+// once the catalog is decoded. The channel is closed at the end of the catalog or at
+// the first error, reported by Err. Callers stopping before the end must Close the decoder.
+//
+//	for ctrl := range d.Controls() {
+//		...
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+func (d *Decoder) Controls() <-chan *catalog.Control {
+	if d.controls != nil {
+		return d.controls
+	}
+	d.controls = make(chan *catalog.Control)
+	go func() {
+		defer close(d.controls)
+		format, err := d.format()
+		if err == nil {
+			switch format {
+			case '<':
+				err = d.streamXML()
+			case '{':
+				err = d.streamJSON()
+			default:
+				err = d.streamYAML()
+			}
+		}
+		if err != errClosed {
+			d.err = err
+		}
+	}()
+	return d.controls
+}
+
+// Err gives the error which stopped the streaming of controls, once their channel is closed
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Close stops the streaming of controls
+func (d *Decoder) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	return nil
+}
+
+// send hands a control over to the reader of the channel, unless the decoder is closed
+func (d *Decoder) send(ctrl *catalog.Control) error {
+	select {
+	case d.controls <- ctrl:
+		return nil
+	case <-d.done:
+		return errClosed
+	}
+}
+
+// streamXML decodes the controls of the catalog and of its groups, skipping every other element
+func (d *Decoder) streamXML() error {
+	x := xml.NewDecoder(d.r)
+	inCatalog := false
+	for {
+		token, err := x.Token()
+		if err == io.EOF {
+			if !inCatalog {
+				return errors.New("not an OSCAL catalog")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "catalog":
+			inCatalog = true
+		case "group":
+		case "control":
+			if !inCatalog {
+				return errors.New("not an OSCAL catalog")
+			}
+			var ctrl catalog.Control
+			if err := x.DecodeElement(&ctrl, &start); err != nil {
+				return err
+			}
+			if err := d.send(&ctrl); err != nil {
+				return err
+			}
+		default:
+			if !inCatalog {
+				return fmt.Errorf("not an OSCAL catalog but a %s", start.Name.Local)
+			}
+			if err := x.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// streamJSON decodes the controls of the catalog and of its groups, skipping every other value
+func (d *Decoder) streamJSON() error {
+	j := json.NewDecoder(d.r)
+	if err := expectDelim(j, '{'); err != nil {
+		return err
+	}
+	for j.More() {
+		key, err := j.Token()
+		if err != nil {
+			return err
+		}
+		if key == "catalog" {
+			if err := expectDelim(j, '{'); err != nil {
+				return err
+			}
+			return d.streamJSONGroup(j)
+		}
+		if err := skipValue(j); err != nil {
+			return err
+		}
+	}
+	return errors.New("not an OSCAL catalog")
+}
+
+// streamJSONGroup streams the controls of the catalog or group object being decoded, up to its end
+func (d *Decoder) streamJSONGroup(j *json.Decoder) error {
+	for j.More() {
+		key, err := j.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "controls":
+			if err := expectDelim(j, '['); err != nil {
+				return err
+			}
+			for j.More() {
+				var ctrl catalog.Control
+				if err := decodeJSONValue(j, &ctrl); err != nil {
+					return err
+				}
+				if err := d.send(&ctrl); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(j, ']'); err != nil {
+				return err
+			}
+		case "groups":
+			if err := expectDelim(j, '['); err != nil {
+				return err
+			}
+			for j.More() {
+				if err := expectDelim(j, '{'); err != nil {
+					return err
+				}
+				if err := d.streamJSONGroup(j); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(j, ']'); err != nil {
+				return err
+			}
+		default:
+			if err := skipValue(j); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(j, '}')
+}
+
+// streamYAML decodes the whole catalog and sends its controls
+func (d *Decoder) streamYAML() error {
+	o, err := d.decodeYAML()
+	if err != nil {
+		return err
+	}
+	if o.Catalog == nil {
+		return errors.New("not an OSCAL catalog")
+	}
+	for _, ctrl := range catalog.NewIndex(o.Catalog).Controls() {
+		if err := d.send(ctrl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expectDelim(j *json.Decoder, delim json.Delim) error {
+	token, err := j.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// skipValue reads past the next value, however deeply nested, without decoding it
+func skipValue(j *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := j.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package oscal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+const nistCatalog = "../../test_util/artifacts/NIST_SP-800-53_rev4_catalog.xml"
+
+func TestDecoderDetectsFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		catalog string
+		profile string
+	}{
+		{input: `<?xml version="1.0"?><catalog xmlns="http://csrc.nist.gov/ns/oscal/1.0" id="xml"><title>XML</title></catalog>`, catalog: "xml"},
+		{input: "\n\t  <profile xmlns=\"http://csrc.nist.gov/ns/oscal/1.0\" id=\"xml-profile\"></profile>", profile: "xml-profile"},
+		{input: `  {"catalog": {"id": "json", "title": "JSON"}}`, catalog: "json"},
+		{input: `{"comment": {"nested": [1, {"catalog": {}}]}, "profile": {"id": "json-profile"}}`, profile: "json-profile"},
+		{input: "catalog:\n  id: yaml\n  title: YAML\n", catalog: "yaml"},
+		{input: "\n---\nprofile:\n  id: yaml-profile\n", profile: "yaml-profile"},
+	}
+	for _, test := range tests {
+		o, err := NewDecoder(strings.NewReader(test.input)).Decode()
+		if err != nil {
+			t.Errorf("cannot decode %s: %v", test.input, err)
+			continue
+		}
+		if test.catalog != "" && (o.Catalog == nil || o.Catalog.Id != test.catalog) {
+			t.Errorf("expected catalog %s from %s, got %+v", test.catalog, test.input, o)
+		}
+		if test.profile != "" && (o.Profile == nil || o.Profile.ID != test.profile) {
+			t.Errorf("expected profile %s from %s, got %+v", test.profile, test.input, o)
+		}
+	}
+
+	for _, input := range []string{"", "   ", "catalog:", "neither: true", "- catalog", `{"neither": true}`, "<html></html>"} {
+		if _, err := NewDecoder(strings.NewReader(input)).Decode(); err == nil {
+			t.Errorf("expected an error decoding %q", input)
+		}
+	}
+}
+
+func TestDecoderControls(t *testing.T) {
+	b, err := ioutil.ReadFile(nistCatalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := New(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var asJSON bytes.Buffer
+	if err := o.JSON(&asJSON, false); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := New(bytes.NewReader(asJSON.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var asYAML bytes.Buffer
+	if err := o.YAML(&asYAML); err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := New(bytes.NewReader(asYAML.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for format, test := range map[string]struct {
+		input    []byte
+		expected *catalog.Catalog
+	}{
+		"xml":  {b, o.Catalog},
+		"json": {asJSON.Bytes(), fromJSON.Catalog},
+		"yaml": {asYAML.Bytes(), fromYAML.Catalog},
+	} {
+		d := NewDecoder(bytes.NewReader(test.input))
+		var streamed []catalog.Control
+		for ctrl := range d.Controls() {
+			streamed = append(streamed, *ctrl)
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("cannot stream the controls of the %s catalog: %v", format, err)
+			continue
+		}
+		var expected []catalog.Control
+		for _, ctrl := range catalog.NewIndex(test.expected).Controls() {
+			expected = append(expected, *ctrl)
+		}
+		if len(streamed) != len(expected) {
+			t.Errorf("expected %d controls from the %s catalog, got %d", len(expected), format, len(streamed))
+			continue
+		}
+		if !reflect.DeepEqual(streamed, expected) {
+			t.Errorf("controls streamed from the %s catalog differ from the decoded ones", format)
+		}
+	}
+}
+
+func TestDecoderControlsStop(t *testing.T) {
+	f, err := ioutil.ReadFile(nistCatalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(bytes.NewReader(f))
+	controls := d.Controls()
+	first := <-controls
+	if first == nil || first.Id != "ac-1" {
+		t.Fatalf("expected ac-1 first, got %+v", first)
+	}
+	d.Close()
+	// the channel is closed shortly after, with at most one more control in flight
+	n := 0
+	for range controls {
+		n++
+	}
+	if n > 1 {
+		t.Errorf("expected streaming to stop once closed, got %d more controls", n)
+	}
+	if err := d.Err(); err != nil {
+		t.Errorf("closing the decoder should not be an error, got %v", err)
+	}
+
+	d = NewDecoder(strings.NewReader(`<profile xmlns="http://csrc.nist.gov/ns/oscal/1.0" id="p"></profile>`))
+	for range d.Controls() {
+		t.Error("profiles have no controls to stream")
+	}
+	if d.Err() == nil {
+		t.Error("expected an error streaming the controls of a profile")
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	for _, name := range []string{nistCatalog, "../../test_util/artifacts/FedRAMP_LOW-baseline_profile.xml"} {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o, err := New(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var asYAML, expected, actual bytes.Buffer
+		if err := o.YAML(&asYAML); err != nil {
+			t.Fatal(err)
+		}
+		fromYAML, err := New(&asYAML)
+		if err != nil {
+			t.Fatalf("cannot read back the YAML of %s: %v", name, err)
+		}
+		// prose and hrefs are compared through their JSON form, XML prose keeping its markup aside
+		if err := o.JSON(&expected, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := fromYAML.JSON(&actual, true); err != nil {
+			t.Fatal(err)
+		}
+		if expected.String() != actual.String() {
+			t.Errorf("%s read back from YAML differs from the original", name)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+//go:build ignore
+// +build ignore
+
+// gen_deepcopy generates DeepCopy and DeepCopyInto methods for the struct types declared in the
+// given files of a package. Fields of types declared in another package, such as catalog types
+// used by profiles, are copied with the methods generated for that package, declared with -dep.
+//
+//	go run ../gen_deepcopy.go -o deepcopy.go [-dep catalog=../catalog] files...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// typeKinds tells whether the named types of a package are structs
+type typeKinds map[string]bool
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen_deepcopy: ")
+	out := flag.String("o", "deepcopy.go", "output file")
+	dep := flag.String("dep", "", "package the types of the files refer to, as name=dir")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("no files to generate deep copies for")
+	}
+
+	fset := token.NewFileSet()
+	var pkg string
+	var specs []*ast.TypeSpec
+	local := make(typeKinds)
+	imports := make(map[string]string)
+	for _, name := range flag.Args() {
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pkg = f.Name.Name
+		for _, spec := range f.Imports {
+			path := strings.Trim(spec.Path.Value, `"`)
+			imports[filepath.Base(path)] = path
+			if spec.Name != nil {
+				imports[spec.Name.Name] = path
+			}
+		}
+		for _, ts := range typeSpecs(f) {
+			_, isStruct := ts.Type.(*ast.StructType)
+			local[ts.Name.Name] = isStruct
+			if isStruct {
+				specs = append(specs, ts)
+			}
+		}
+	}
+	deps := make(map[string]typeKinds)
+	if *dep != "" {
+		parts := strings.SplitN(*dep, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("invalid dependency %s, expected name=dir", *dep)
+		}
+		kinds, err := packageKinds(fset, parts[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		deps[parts[0]] = kinds
+	}
+
+	g := generator{local: local, deps: deps, used: make(map[string]bool)}
+	var types []generatedType
+	for _, ts := range specs {
+		t := generatedType{Name: ts.Name.Name}
+		for _, field := range ts.Type.(*ast.StructType).Fields.List {
+			for _, name := range fieldNames(field) {
+				stmt, err := g.copyField(name, field.Type)
+				if err != nil {
+					log.Fatalf("%s.%s: %v", ts.Name.Name, name, err)
+				}
+				if stmt != "" {
+					t.Fields = append(t.Fields, stmt)
+				}
+			}
+		}
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	var used []string
+	for name := range g.used {
+		path, ok := imports[name]
+		if !ok {
+			log.Fatalf("no import of package %s", name)
+		}
+		used = append(used, path)
+	}
+	sort.Strings(used)
+
+	var buf bytes.Buffer
+	if err := deepCopyTemplate.Execute(&buf, struct {
+		Package string
+		Imports []string
+		Types   []generatedType
+	}{pkg, used, types}); err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("cannot format generated code: %v\n%s", err, buf.String())
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generatedType struct {
+	Name   string
+	Fields []string
+}
+
+var deepCopyTemplate = template.Must(template.New("deepcopy").Parse(`// Code generated by go generate; DO NOT EDIT.
+package {{ .Package }}
+{{ if .Imports }}
+import (
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+{{ end }}
+{{- range .Types }}
+// DeepCopy copies the {{ .Name }}, sharing no memory with it
+func (in *{{ .Name }}) DeepCopy() *{{ .Name }} {
+	if in == nil {
+		return nil
+	}
+	out := new({{ .Name }})
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the {{ .Name }} into out
+func (in *{{ .Name }}) DeepCopyInto(out *{{ .Name }}) {
+	*out = *in
+	{{- range .Fields }}
+	{{ . }}
+	{{- end }}
+}
+{{ end }}`))
+
+func typeSpecs(f *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			specs = append(specs, spec.(*ast.TypeSpec))
+		}
+	}
+	return specs
+}
+
+func packageKinds(fset *token.FileSet, dir string) (typeKinds, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(typeKinds)
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, ts := range typeSpecs(f) {
+			_, isStruct := ts.Type.(*ast.StructType)
+			kinds[ts.Name.Name] = isStruct
+		}
+	}
+	return kinds, nil
+}
+
+// fieldNames names the fields of a declaration, embedded fields by their type
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) > 0 {
+		var names []string
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+		return names
+	}
+	t := field.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if sel, ok := t.(*ast.SelectorExpr); ok {
+		return []string{sel.Sel.Name}
+	}
+	return []string{t.(*ast.Ident).Name}
+}
+
+type generator struct {
+	local typeKinds
+	deps  map[string]typeKinds
+	// used collects the packages the generated code refers to
+	used map[string]bool
+}
+
+// kind classifies a type expression as a value copied by assignment, a struct with generated
+// deep copy methods or a struct of another package copied by value
+type kind int
+
+const (
+	kindValue kind = iota
+	kindGenerated
+	kindForeign
+	kindUnsupported
+)
+
+var basicTypes = map[string]bool{
+	"string": true, "bool": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+func (g generator) kindOf(t ast.Expr) kind {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if basicTypes[t.Name] {
+			return kindValue
+		}
+		isStruct, ok := g.local[t.Name]
+		if !ok {
+			return kindUnsupported
+		}
+		if isStruct {
+			return kindGenerated
+		}
+		return kindValue
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		if kinds, ok := g.deps[pkg]; ok {
+			isStruct, ok := kinds[t.Sel.Name]
+			if !ok {
+				return kindUnsupported
+			}
+			if isStruct {
+				return kindGenerated
+			}
+			return kindValue
+		}
+		// structs of other packages, such as xml.Name and url.URL, hold values only
+		return kindForeign
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			if g.kindOf(field.Type) != kindValue {
+				return kindUnsupported
+			}
+		}
+		return kindValue
+	}
+	return kindUnsupported
+}
+
+// copyField gives the statement deep copying a field once the struct is copied by value
+func (g generator) copyField(name string, t ast.Expr) (string, error) {
+	switch t := t.(type) {
+	case *ast.StarExpr:
+		switch g.kindOf(t.X) {
+		case kindGenerated:
+			return fmt.Sprintf("out.%s = in.%s.DeepCopy()", name, name), nil
+		case kindValue, kindForeign:
+			return fmt.Sprintf("if in.%[1]s != nil {\nv := *in.%[1]s\nout.%[1]s = &v\n}", name), nil
+		}
+	case *ast.ArrayType:
+		if t.Len != nil {
+			break
+		}
+		elem := exprString(t.Elt)
+		if sel, ok := t.Elt.(*ast.SelectorExpr); ok {
+			g.used[sel.X.(*ast.Ident).Name] = true
+		}
+		switch g.kindOf(t.Elt) {
+		case kindGenerated:
+			return fmt.Sprintf("if in.%[1]s != nil {\nout.%[1]s = make([]%[2]s, len(in.%[1]s))\nfor i := range in.%[1]s {\nin.%[1]s[i].DeepCopyInto(&out.%[1]s[i])\n}\n}", name, elem), nil
+		case kindValue, kindForeign:
+			return fmt.Sprintf("if in.%[1]s != nil {\nout.%[1]s = make([]%[2]s, len(in.%[1]s))\ncopy(out.%[1]s, in.%[1]s)\n}", name, elem), nil
+		}
+	default:
+		switch g.kindOf(t) {
+		case kindGenerated:
+			return fmt.Sprintf("in.%[1]s.DeepCopyInto(&out.%[1]s)", name), nil
+		case kindValue, kindForeign:
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("cannot deep copy a field of type %s", exprString(t))
+}
+
+func exprString(t ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), t); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,429 @@
+package oscal
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+// JSONEncoding tells how OSCAL documents are written to JSON
+type JSONEncoding string
+
+const (
+	// LegacyJSON is the JSON encoding of the oscalkit types, with camelCase keys and prose as a
+	// list of markup blocks
+	LegacyJSON JSONEncoding = "legacy"
+	// OfficialJSON is the JSON representation of the OSCAL metaschema: flags and fields are named
+	// after the XML model, such as model-version and control-id, lists after their group-as, such
+	// as param-settings, prose is a single string of markup and as-is is a boolean
+	OfficialJSON JSONEncoding = "official"
+)
+
+// EncodeJSON writes the OSCAL object as JSON in the given encoding to the given writer
+func (o *OSCAL) EncodeJSON(w io.Writer, prettify bool, encoding JSONEncoding) error {
+	switch encoding {
+	case LegacyJSON, "":
+		return o.JSON(w, prettify)
+	case OfficialJSON:
+	default:
+		return fmt.Errorf("unknown JSON encoding %s, expected %s or %s", encoding, LegacyJSON, OfficialJSON)
+	}
+
+	legacy, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(legacy))
+	d.UseNumber()
+	tree, err := readValue(d)
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(w)
+	if prettify {
+		e.SetIndent("", "  ")
+	}
+	return e.Encode(toOfficial(tree, reflect.TypeOf(o).Elem()))
+}
+
+// decodeJSONValue decodes the next value of d into v, read in either JSON encoding. Values only
+// made of legacy keys and shapes are decoded as they are, others are converted to the legacy
+// encoding first.
+func decodeJSONValue(d *json.Decoder, v interface{}) error {
+	var raw json.RawMessage
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	strict := json.NewDecoder(bytes.NewReader(raw))
+	strict.DisallowUnknownFields()
+	if err := strict.Decode(v); err == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	reflect.ValueOf(v).Elem().Set(reflect.Zero(t))
+	official := json.NewDecoder(bytes.NewReader(raw))
+	official.UseNumber()
+	var tree interface{}
+	if err := official.Decode(&tree); err != nil {
+		return err
+	}
+	legacy, err := json.Marshal(toLegacy(tree, t))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(legacy, v)
+}
+
+// member is a member of a JSON object
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is a JSON object keeping its members in document order
+type object []member
+
+// MarshalJSON writes the members in order
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// readValue reads the next JSON value of d as objects, slices and the tokens of d, keeping the
+// order of the members of objects as written
+func readValue(d *json.Decoder) (interface{}, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		o := object{}
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readValue(d)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, member{key.(string), value})
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return o, nil
+
+	case json.Delim('['):
+		a := []interface{}{}
+		for d.More() {
+			value, err := readValue(d)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+	return token, nil
+}
+
+// jsonField is a field of a struct along with its names in both encodings
+type jsonField struct {
+	legacy   string
+	official string
+	typ      reflect.Type
+}
+
+// jsonFields are the fields of a struct by their legacy and official names
+type jsonFields struct {
+	byLegacy   map[string]*jsonField
+	byOfficial map[string]*jsonField
+}
+
+var (
+	proseType = reflect.TypeOf(catalog.Prose{})
+	asIsType  = reflect.TypeOf(profile.AsIs(""))
+
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+	fieldsCache sync.Map
+)
+
+// fieldsOf names the fields of a struct type in both encodings. The official name of a list is
+// the group-as name the metaschema gives it, which the generated types name the field after, as
+// param-settings for ParamSettings. The official name of any other field is its XML name.
+func fieldsOf(t reflect.Type) *jsonFields {
+	if cached, ok := fieldsCache.Load(t); ok {
+		return cached.(*jsonFields)
+	}
+	fields := &jsonFields{byLegacy: make(map[string]*jsonField), byOfficial: make(map[string]*jsonField)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		legacy := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if sf.PkgPath != "" || legacy == "-" {
+			continue
+		}
+		if legacy == "" {
+			legacy = sf.Name
+		}
+		f := &jsonField{legacy: legacy, official: kebab(sf.Name), typ: sf.Type}
+		if sf.Type.Kind() != reflect.Slice {
+			f.official = xmlName(sf.Tag.Get("xml"), kebab(legacy))
+		}
+		fields.byLegacy[f.legacy] = f
+		fields.byOfficial[f.official] = f
+	}
+	fieldsCache.Store(t, fields)
+	return fields
+}
+
+// xmlName gives the name of an attribute or element from its xml tag, or name for text content
+func xmlName(tag, name string) string {
+	parts := strings.Split(tag, ",")
+	switch {
+	case parts[0] == "" || parts[0] == "-":
+		for _, option := range parts[1:] {
+			if option == "chardata" {
+				return "value"
+			}
+		}
+		return name
+	case strings.Contains(parts[0], " "):
+		// namespaced name
+		return parts[0][strings.LastIndex(parts[0], " ")+1:]
+	}
+	return parts[0]
+}
+
+// kebab converts a camelCase name to kebab-case
+func kebab(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isLeaf tells whether values of the type are written by their own marshalers
+func isLeaf(t reflect.Type) bool {
+	return t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) ||
+		reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+func elem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// toOfficial converts a legacy JSON value of the given type to the official encoding
+func toOfficial(v interface{}, t reflect.Type) interface{} {
+	t = elem(t)
+	switch {
+	case t == proseType:
+		blocks, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		var prose []string
+		for _, block := range blocks {
+			prose = append(prose, fmt.Sprint(block))
+		}
+		return strings.Join(prose, "")
+
+	case t == asIsType:
+		if v == "true" {
+			return true
+		}
+		return v
+
+	case t.Kind() == reflect.Slice:
+		a, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		for i := range a {
+			a[i] = toOfficial(a[i], t.Elem())
+		}
+		return a
+
+	case t.Kind() == reflect.Struct && !isLeaf(t):
+		o, ok := v.(object)
+		if !ok {
+			return v
+		}
+		fields := fieldsOf(t)
+		for i, m := range o {
+			if f, ok := fields.byLegacy[m.key]; ok {
+				o[i] = member{f.official, toOfficial(m.value, f.typ)}
+			}
+		}
+		return o
+	}
+	return v
+}
+
+// isOfficial tells whether a JSON value of the given type, read with readValue, is written in the
+// official encoding rather than the legacy one
+func isOfficial(v interface{}, t reflect.Type) bool {
+	t = elem(t)
+	switch {
+	case t == proseType:
+		_, ok := v.(string)
+		return ok
+
+	case t == asIsType:
+		_, ok := v.(bool)
+		return ok
+
+	case t.Kind() == reflect.Slice:
+		a, _ := v.([]interface{})
+		for _, value := range a {
+			if isOfficial(value, t.Elem()) {
+				return true
+			}
+		}
+
+	case t.Kind() == reflect.Struct && !isLeaf(t):
+		o, _ := v.(object)
+		fields := fieldsOf(t)
+		for _, m := range o {
+			if f, ok := fields.byLegacy[m.key]; ok {
+				if isOfficial(m.value, f.typ) {
+					return true
+				}
+				continue
+			}
+			if _, ok := fields.byOfficial[m.key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// toLegacy converts a JSON value of the given type, in either encoding, to the legacy encoding.
+// Objects are maps, the order of their members not mattering once decoded.
+func toLegacy(v interface{}, t reflect.Type) interface{} {
+	t = elem(t)
+	switch {
+	case t == proseType:
+		if prose, ok := v.(string); ok {
+			return proseBlocks(prose)
+		}
+		return v
+
+	case t == asIsType:
+		if b, ok := v.(bool); ok {
+			return fmt.Sprint(b)
+		}
+		return v
+
+	case t.Kind() == reflect.Slice:
+		a, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		for i := range a {
+			a[i] = toLegacy(a[i], t.Elem())
+		}
+		return a
+
+	case t.Kind() == reflect.Struct && !isLeaf(t):
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		fields := fieldsOf(t)
+		legacy := make(map[string]interface{}, len(o))
+		for key, value := range o {
+			f, ok := fields.byOfficial[key]
+			if !ok {
+				f, ok = fields.byLegacy[key]
+			}
+			if ok {
+				key, value = f.legacy, toLegacy(value, f.typ)
+			}
+			legacy[key] = value
+		}
+		return legacy
+	}
+	return v
+}
+
+// proseBlocks splits a string of prose markup into its top-level blocks, as the legacy encoding
+// lists them
+func proseBlocks(prose string) []interface{} {
+	blocks := []interface{}{}
+	d := xml.NewDecoder(strings.NewReader(prose))
+	depth := 0
+	var start int64
+	for {
+		offset := d.InputOffset()
+		token, err := d.Token()
+		if err != nil {
+			if err != io.EOF || depth > 0 {
+				// not well-formed, kept whole
+				return []interface{}{prose}
+			}
+			return blocks
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				start = offset
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				blocks = append(blocks, prose[start:d.InputOffset()])
+			}
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(token)) > 0 {
+				blocks = append(blocks, string(token))
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package oscal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema"
+)
+
+var officialJSONArtifacts = []string{
+	nistCatalog,
+	"../../test_util/artifacts/FedRAMP_HIGH-baseline_profile.xml",
+	"../../test_util/artifacts/FedRAMP_LOW-baseline_profile.xml",
+	"../../test_util/artifacts/FedRAMP_MODERATE-baseline_profile.xml",
+	"../../test_util/artifacts/NIST_SP-800-53_rev4_HIGH-baseline_profile.xml",
+	"../../test_util/artifacts/NIST_SP-800-53_rev4_LOW-baseline_profile.xml",
+	"../../test_util/artifacts/NIST_SP-800-53_rev4_MODERATE-baseline_profile.xml",
+}
+
+func readArtifact(t *testing.T, name string) *OSCAL {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	o, err := New(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func encodeJSON(t *testing.T, o *OSCAL, encoding JSONEncoding) []byte {
+	var buf bytes.Buffer
+	if err := o.EncodeJSON(&buf, true, encoding); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOfficialJSONShapes(t *testing.T) {
+	var doc struct {
+		Catalog map[string]interface{}
+		Profile map[string]interface{}
+	}
+	if err := json.Unmarshal(encodeJSON(t, readArtifact(t, nistCatalog), OfficialJSON), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Catalog["model-version"]; !ok {
+		t.Error("expected the kebab-case model-version key")
+	}
+	group := doc.Catalog["groups"].([]interface{})[0].(map[string]interface{})
+	ctrl := group["controls"].([]interface{})[0].(map[string]interface{})
+	part := ctrl["parts"].([]interface{})[0].(map[string]interface{})
+	if prose, ok := part["parts"].([]interface{})[0].(map[string]interface{})["prose"].(string); !ok || !strings.HasPrefix(prose, "<p>") {
+		t.Errorf("expected prose as a string of markup, got %v", part["parts"])
+	}
+
+	b := encodeJSON(t, readArtifact(t, "../../test_util/artifacts/NIST_SP-800-53_rev4_LOW-baseline_profile.xml"), OfficialJSON)
+	for _, legacy := range []string{`"controlId"`, `"withSubcontrols"`, `"asIs"`, `"calls"`, `"set-params"`, `"alters"`, `"adds"`} {
+		if bytes.Contains(b, []byte(legacy)) {
+			t.Errorf("official JSON should not have the legacy key %s", legacy)
+		}
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Profile["merge"].(map[string]interface{})["as-is"] != true {
+		t.Errorf("expected as-is as a boolean, got %v", doc.Profile["merge"])
+	}
+	imp := doc.Profile["imports"].([]interface{})[0].(map[string]interface{})
+	call := imp["include"].(map[string]interface{})["id-selectors"].([]interface{})[0].(map[string]interface{})
+	if _, ok := call["control-id"]; !ok {
+		t.Errorf("expected calls by control-id, got %v", call)
+	}
+	alterations, ok := doc.Profile["modify"].(map[string]interface{})["alterations"].([]interface{})
+	if !ok {
+		t.Fatalf("expected the alterations list named after its group-as, got %v", doc.Profile["modify"])
+	}
+	if _, ok := alterations[0].(map[string]interface{})["additions"].([]interface{}); !ok {
+		t.Errorf("expected the additions of an alteration, got %v", alterations[0])
+	}
+
+	b = encodeJSON(t, readArtifact(t, "../../test_util/artifacts/FedRAMP_LOW-baseline_profile.xml"), OfficialJSON)
+	if !bytes.Contains(b, []byte(`"param-settings": [`)) {
+		t.Error("expected the param-settings list named after its group-as")
+	}
+}
+
+func TestOfficialJSONRoundTrip(t *testing.T) {
+	for _, name := range officialJSONArtifacts {
+		o := readArtifact(t, name)
+		legacy := encodeJSON(t, o, LegacyJSON)
+		official := encodeJSON(t, o, OfficialJSON)
+
+		fromOfficial, err := New(bytes.NewReader(official))
+		if err != nil {
+			t.Fatalf("cannot read back the official JSON of %s: %v", name, err)
+		}
+		if actual := encodeJSON(t, fromOfficial, LegacyJSON); !bytes.Equal(actual, legacy) {
+			t.Errorf("%s read back from official JSON differs from the original", name)
+		}
+		if again := encodeJSON(t, fromOfficial, OfficialJSON); !bytes.Equal(again, official) {
+			t.Errorf("writing %s again as official JSON should give the same", name)
+		}
+	}
+}
+
+func TestProseBlocks(t *testing.T) {
+	blocks := proseBlocks(`<p>first <em>one</em></p> <ul><li>second</li></ul>`)
+	if len(blocks) != 2 || blocks[0] != "<p>first <em>one</em></p>" || blocks[1] != "<ul><li>second</li></ul>" {
+		t.Errorf("expected the top-level blocks of prose, got %q", blocks)
+	}
+	if blocks := proseBlocks("<p>unclosed"); len(blocks) != 1 || blocks[0] != "<p>unclosed" {
+		t.Errorf("expected malformed prose kept whole, got %q", blocks)
+	}
+}
+
+// TestOfficialJSONSchemas validates the official JSON written for the artifacts against the
+// OSCAL JSON schemas published by NIST, vendored in test_util/artifacts next to the XML schemas
+// of the same release from schema/json of https://github.com/usnistgov/OSCAL, or against those
+// in the directory named by OSCAL_JSON_SCHEMAS. JSON in the legacy encoding must not pass.
+func TestOfficialJSONSchemas(t *testing.T) {
+	dir := os.Getenv("OSCAL_JSON_SCHEMAS")
+	if dir == "" {
+		dir = "../../test_util/artifacts"
+	}
+	schemas := make(map[string]*jsonschema.Schema)
+	for _, model := range []string{"catalog", "profile"} {
+		path := filepath.Join(dir, "oscal-"+model+"-schema.json")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			t.Skipf("%s is missing, vendor it from https://github.com/usnistgov/OSCAL/tree/master/schema/json", path)
+		}
+		schema, err := jsonschema.Compile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemas[model] = schema
+	}
+
+	for _, name := range officialJSONArtifacts {
+		o := readArtifact(t, name)
+		model := "profile"
+		if o.Catalog != nil {
+			model = "catalog"
+		}
+		if err := schemas[model].Validate(bytes.NewReader(encodeJSON(t, o, OfficialJSON))); err != nil {
+			t.Errorf("official JSON of %s is not valid against the %s schema: %v", name, model, err)
+		}
+		if err := schemas[model].Validate(bytes.NewReader(encodeJSON(t, o, LegacyJSON))); err == nil {
+			t.Errorf("legacy JSON of %s should not be valid against the %s schema", name, model)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package oscal
 
 import (
-	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"io"
-	"io/ioutil"
 
 	"github.com/docker/oscalkit/types/oscal/catalog"
 	"github.com/docker/oscalkit/types/oscal/profile"
@@ -98,59 +96,7 @@ func (o *OSCAL) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 // New returns a concrete OSCAL type from a reader
 func New(r io.Reader) (*OSCAL, error) {
-	oscalBytes, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-
-	d := xml.NewDecoder(bytes.NewReader(oscalBytes))
-	for {
-		token, err := d.Token()
-		if err != nil || token == nil {
-			break
-		}
-		switch startElement := token.(type) {
-		case xml.StartElement:
-			switch startElement.Name.Local {
-			case "catalog":
-				var catalog catalog.Catalog
-				if err := d.DecodeElement(&catalog, &startElement); err != nil {
-					return nil, err
-				}
-				return &OSCAL{Catalog: &catalog}, nil
-
-			case "profile":
-				var profile profile.Profile
-				if err := d.DecodeElement(&profile, &startElement); err != nil {
-					return nil, err
-				}
-				return &OSCAL{Profile: &profile}, nil
-			}
-		}
-	}
-
-	var oscalT map[string]json.RawMessage
-	if err := json.Unmarshal(oscalBytes, &oscalT); err == nil {
-		for k, v := range oscalT {
-			switch k {
-			case "catalog":
-				var catalog catalog.Catalog
-				if err := json.Unmarshal(v, &catalog); err != nil {
-					return nil, err
-				}
-				return &OSCAL{Catalog: &catalog}, nil
-
-			case "profile":
-				var profile profile.Profile
-				if err := json.Unmarshal(v, &profile); err != nil {
-					return nil, err
-				}
-				return &OSCAL{Profile: &profile}, nil
-			}
-		}
-	}
-
-	return nil, errors.New("Malformed OSCAL. Must be XML or JSON")
+	return NewDecoder(r).Decode()
 }
 
 // XML writes the OSCAL object as XML to the given writer
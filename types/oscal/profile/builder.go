@@ -0,0 +1,393 @@
+package profile
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+// Builder builds a profile in code. Include and exclude directives apply to the last import, and
+// are checked against its catalog when it is imported with ImportCatalog.
+//
+//	p, err := profile.NewBuilder("my-baseline", "My baseline").
+//		ImportCatalog("NIST_SP-800-53_rev4_catalog.xml", nistCatalog).
+//		IncludeControls(true, "ac-1", "ac-2").
+//		ExcludeSubcontrols("ac-2.1").
+//		SetParam("ac-1_prm_2", "at least annually").
+//		Build()
+type Builder struct {
+	profile Profile
+	// catalogs are the catalogs of the imports, nil when not known
+	catalogs []*catalogIDs
+	errs     []string
+}
+
+// NewBuilder starts a profile with the given id and title
+func NewBuilder(id, title string) *Builder {
+	return &Builder{profile: Profile{ID: id, Title: title}}
+}
+
+func (b *Builder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
+
+// Import adds an import of the catalog or profile at href
+func (b *Builder) Import(href string) *Builder {
+	return b.addImport(href, nil)
+}
+
+// ImportCatalog adds an import of a catalog at href, checking the directives of the import
+// as well as the alters and set-params of the profile against the catalog
+func (b *Builder) ImportCatalog(href string, c *catalog.Catalog) *Builder {
+	return b.addImport(href, newCatalogIDs(c))
+}
+
+func (b *Builder) addImport(href string, ids *catalogIDs) *Builder {
+	u, err := url.Parse(href)
+	if err != nil || href == "" {
+		b.errorf("invalid import href %q", href)
+		u = &url.URL{}
+	}
+	b.profile.Imports = append(b.profile.Imports, Import{Href: &catalog.Href{URL: u}})
+	b.catalogs = append(b.catalogs, ids)
+	return b
+}
+
+// current is the last import, nil when there is none yet
+func (b *Builder) current(directive string) (*Import, *catalogIDs) {
+	if len(b.profile.Imports) == 0 {
+		b.errorf("%s before any import", directive)
+		return nil, nil
+	}
+	i := len(b.profile.Imports) - 1
+	return &b.profile.Imports[i], b.catalogs[i]
+}
+
+func (b *Builder) include(directive string) (*Include, *catalogIDs) {
+	imp, ids := b.current(directive)
+	if imp == nil {
+		return nil, nil
+	}
+	if imp.Include == nil {
+		imp.Include = &Include{}
+	}
+	return imp.Include, ids
+}
+
+func (b *Builder) exclude(directive string) (*Exclude, *catalogIDs) {
+	imp, ids := b.current(directive)
+	if imp == nil {
+		return nil, nil
+	}
+	if imp.Exclude == nil {
+		imp.Exclude = &Exclude{}
+	}
+	return imp.Exclude, ids
+}
+
+func yesNo(flag bool) string {
+	if flag {
+		return "yes"
+	}
+	return "no"
+}
+
+// IncludeAll includes all the controls of the last import
+func (b *Builder) IncludeAll(withSubcontrols bool) *Builder {
+	if include, _ := b.include("include all"); include != nil {
+		include.All = &All{WithSubcontrols: yesNo(withSubcontrols)}
+	}
+	return b
+}
+
+// IncludeControls includes controls of the last import by id
+func (b *Builder) IncludeControls(withSubcontrols bool, ids ...string) *Builder {
+	include, known := b.include("include of controls")
+	if include == nil {
+		return b
+	}
+	for _, id := range ids {
+		if known != nil && !known.controls[strings.ToLower(id)] {
+			b.errorf("control %s is not in the catalog of import %d", id, len(b.profile.Imports))
+		}
+		include.IdSelectors = append(include.IdSelectors, Call{ControlId: id, WithSubcontrols: yesNo(withSubcontrols)})
+	}
+	return b
+}
+
+// IncludeSubcontrols includes subcontrols of the last import by id, along with their control
+func (b *Builder) IncludeSubcontrols(ids ...string) *Builder {
+	include, known := b.include("include of subcontrols")
+	if include == nil {
+		return b
+	}
+	for _, id := range ids {
+		if known != nil && !known.subcontrols[strings.ToLower(id)] {
+			b.errorf("subcontrol %s is not in the catalog of import %d", id, len(b.profile.Imports))
+		}
+		include.IdSelectors = append(include.IdSelectors, Call{SubcontrolId: id, WithControl: "yes"})
+	}
+	return b
+}
+
+// IncludeIDs includes controls and subcontrols of the last import by id, telling them apart with
+// the catalog of the import. Without a catalog every id is included as a control.
+func (b *Builder) IncludeIDs(withSubcontrols bool, ids ...string) *Builder {
+	_, known := b.current("include of ids")
+	for _, id := range ids {
+		if known != nil && known.subcontrols[strings.ToLower(id)] {
+			b.IncludeSubcontrols(id)
+			continue
+		}
+		b.IncludeControls(withSubcontrols, id)
+	}
+	return b
+}
+
+// IncludeFamily includes the controls of the groups of the last import's catalog whose id or
+// title is family, such as ac or Access Control. The catalog of the import must be known.
+func (b *Builder) IncludeFamily(family string, withSubcontrols bool) *Builder {
+	_, known := b.current("include of family " + family)
+	if known == nil {
+		if len(b.profile.Imports) > 0 {
+			b.errorf("family %s cannot be selected without the catalog of import %d", family, len(b.profile.Imports))
+		}
+		return b
+	}
+	controls, ok := known.families[strings.ToLower(family)]
+	if !ok {
+		b.errorf("family %s is not in the catalog of import %d", family, len(b.profile.Imports))
+		return b
+	}
+	return b.IncludeControls(withSubcontrols, controls...)
+}
+
+// IncludeMatch includes the controls and subcontrols of the last import whose id matches a pattern
+func (b *Builder) IncludeMatch(pattern string, withSubcontrols bool) *Builder {
+	include, known := b.include("include of pattern " + pattern)
+	if include != nil && b.checkPattern(pattern, known) {
+		include.PatternSelectors = append(include.PatternSelectors, Match{Pattern: pattern, WithSubcontrols: yesNo(withSubcontrols)})
+	}
+	return b
+}
+
+// ExcludeControls excludes controls of the last import by id
+func (b *Builder) ExcludeControls(ids ...string) *Builder {
+	exclude, known := b.exclude("exclude of controls")
+	if exclude == nil {
+		return b
+	}
+	for _, id := range ids {
+		if known != nil && !known.controls[strings.ToLower(id)] {
+			b.errorf("control %s is not in the catalog of import %d", id, len(b.profile.Imports))
+		}
+		exclude.IdSelectors = append(exclude.IdSelectors, Call{ControlId: id})
+	}
+	return b
+}
+
+// ExcludeSubcontrols excludes subcontrols of the last import by id
+func (b *Builder) ExcludeSubcontrols(ids ...string) *Builder {
+	exclude, known := b.exclude("exclude of subcontrols")
+	if exclude == nil {
+		return b
+	}
+	for _, id := range ids {
+		if known != nil && !known.subcontrols[strings.ToLower(id)] {
+			b.errorf("subcontrol %s is not in the catalog of import %d", id, len(b.profile.Imports))
+		}
+		exclude.IdSelectors = append(exclude.IdSelectors, Call{SubcontrolId: id})
+	}
+	return b
+}
+
+// ExcludeMatch excludes the controls and subcontrols of the last import whose id matches a pattern
+func (b *Builder) ExcludeMatch(pattern string) *Builder {
+	exclude, known := b.exclude("exclude of pattern " + pattern)
+	if exclude != nil && b.checkPattern(pattern, known) {
+		exclude.PatternSelectors = append(exclude.PatternSelectors, Match{Pattern: pattern})
+	}
+	return b
+}
+
+// checkPattern checks that a pattern matches some id of a known catalog, reporting whether it
+// compiles. Patterns match whole ids regardless of case, as in profile resolution.
+func (b *Builder) checkPattern(pattern string, known *catalogIDs) bool {
+	regex, err := regexp.Compile(fmt.Sprintf("(?i)^(?:%s)$", pattern))
+	if err != nil {
+		b.errorf("invalid pattern %s: %v", pattern, err)
+		return false
+	}
+	if known == nil {
+		return true
+	}
+	for id := range known.controls {
+		if regex.MatchString(id) {
+			return true
+		}
+	}
+	for id := range known.subcontrols {
+		if regex.MatchString(id) {
+			return true
+		}
+	}
+	b.errorf("pattern %s matches nothing in the catalog of import %d", pattern, len(b.profile.Imports))
+	return true
+}
+
+func (b *Builder) modify() *Modify {
+	if b.profile.Modify == nil {
+		b.profile.Modify = &Modify{}
+	}
+	return b.profile.Modify
+}
+
+// SetParam sets the value of a parameter
+func (b *Builder) SetParam(id, value string) *Builder {
+	return b.AddSetParam(SetParam{Id: id, Value: catalog.Value(value)})
+}
+
+// AddSetParam adds a parameter setting, such as one constraining the parameter
+func (b *Builder) AddSetParam(sp SetParam) *Builder {
+	b.modify().ParamSettings = append(b.modify().ParamSettings, sp)
+	return b
+}
+
+// AlterControl adds elements to a control
+func (b *Builder) AlterControl(id string, additions ...Add) *Builder {
+	return b.Alter(Alter{ControlId: id, Additions: additions})
+}
+
+// AlterSubcontrol adds elements to a subcontrol
+func (b *Builder) AlterSubcontrol(id string, additions ...Add) *Builder {
+	return b.Alter(Alter{SubcontrolId: id, Additions: additions})
+}
+
+// Alter adds an alter, which may remove elements as well as add some
+func (b *Builder) Alter(alt Alter) *Builder {
+	if alt.ControlId == "" && alt.SubcontrolId == "" {
+		b.errorf("alter without a control or subcontrol id")
+	}
+	b.modify().Alterations = append(b.modify().Alterations, alt)
+	return b
+}
+
+// Merge sets the merge directive of the profile
+func (b *Builder) Merge(m Merge) *Builder {
+	b.profile.Merge = &m
+	return b
+}
+
+// Build checks and returns the profile. Alters and set-params are checked against the catalogs
+// when all of them are known.
+func (b *Builder) Build() (*Profile, error) {
+	errs := append([]string{}, b.errs...)
+	if len(b.profile.Imports) == 0 {
+		errs = append(errs, "no import")
+	}
+	if b.profile.Modify != nil && b.allCatalogsKnown() {
+		for _, alt := range b.profile.Modify.Alterations {
+			if alt.ControlId != "" && !b.anyCatalog(func(ids *catalogIDs) bool { return ids.controls[strings.ToLower(alt.ControlId)] }) {
+				errs = append(errs, fmt.Sprintf("altered control %s is not in any imported catalog", alt.ControlId))
+			}
+			if alt.SubcontrolId != "" && !b.anyCatalog(func(ids *catalogIDs) bool { return ids.subcontrols[strings.ToLower(alt.SubcontrolId)] }) {
+				errs = append(errs, fmt.Sprintf("altered subcontrol %s is not in any imported catalog", alt.SubcontrolId))
+			}
+		}
+		for _, sp := range b.profile.Modify.ParamSettings {
+			if !b.anyCatalog(func(ids *catalogIDs) bool { return ids.params[sp.Id] }) {
+				errs = append(errs, fmt.Sprintf("set parameter %s is not in any imported catalog", sp.Id))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		if b.profile.ID == "" {
+			return nil, fmt.Errorf("invalid profile: %s", strings.Join(errs, "; "))
+		}
+		return nil, fmt.Errorf("invalid profile %s: %s", b.profile.ID, strings.Join(errs, "; "))
+	}
+	p := b.profile
+	return &p, nil
+}
+
+func (b *Builder) allCatalogsKnown() bool {
+	for _, ids := range b.catalogs {
+		if ids == nil {
+			return false
+		}
+	}
+	return len(b.catalogs) > 0
+}
+
+func (b *Builder) anyCatalog(has func(ids *catalogIDs) bool) bool {
+	for _, ids := range b.catalogs {
+		if has(ids) {
+			return true
+		}
+	}
+	return false
+}
+
+// catalogIDs holds the (lower cased) control and subcontrol ids, the parameter ids and the
+// families of a catalog
+type catalogIDs struct {
+	controls    map[string]bool
+	subcontrols map[string]bool
+	params      map[string]bool
+	// families maps the lower cased id and title of each group against the ids of its controls
+	families map[string][]string
+}
+
+func newCatalogIDs(c *catalog.Catalog) *catalogIDs {
+	ids := &catalogIDs{
+		controls:    make(map[string]bool),
+		subcontrols: make(map[string]bool),
+		params:      make(map[string]bool),
+		families:    make(map[string][]string),
+	}
+	if c == nil {
+		return ids
+	}
+	ids.addControls(c.Controls)
+	for _, g := range c.Groups {
+		ids.addGroup(g)
+	}
+	return ids
+}
+
+func (ids *catalogIDs) addGroup(g catalog.Group) {
+	var controls []string
+	for _, ctrl := range g.Controls {
+		controls = append(controls, ctrl.Id)
+	}
+	for _, key := range []string{g.Id, string(g.Title)} {
+		if key != "" {
+			ids.families[strings.ToLower(key)] = controls
+		}
+	}
+	ids.addParams(g.Params)
+	ids.addControls(g.Controls)
+	for _, sub := range g.Groups {
+		ids.addGroup(sub)
+	}
+}
+
+func (ids *catalogIDs) addControls(controls []catalog.Control) {
+	for _, ctrl := range controls {
+		ids.controls[strings.ToLower(ctrl.Id)] = true
+		ids.addParams(ctrl.Params)
+		for _, sc := range ctrl.Subcontrols {
+			ids.subcontrols[strings.ToLower(sc.Id)] = true
+			ids.addParams(sc.Params)
+		}
+	}
+}
+
+func (ids *catalogIDs) addParams(params []catalog.Param) {
+	for _, p := range params {
+		ids.params[p.Id] = true
+	}
+}
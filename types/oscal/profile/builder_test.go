@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+func builderTestCatalog() *catalog.Catalog {
+	return &catalog.Catalog{
+		Groups: []catalog.Group{
+			catalog.Group{
+				Id:    "ac",
+				Title: "Access Control",
+				Controls: []catalog.Control{
+					catalog.Control{Id: "ac-1", Params: []catalog.Param{catalog.Param{Id: "ac-1_prm_1"}}},
+					catalog.Control{
+						Id:          "ac-2",
+						Subcontrols: []catalog.Subcontrol{catalog.Subcontrol{Id: "ac-2.1"}},
+					},
+				},
+			},
+			catalog.Group{
+				Id:       "au",
+				Title:    "Audit and Accountability",
+				Controls: []catalog.Control{catalog.Control{Id: "au-1"}},
+			},
+		},
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	p, err := NewBuilder("baseline", "Baseline").
+		ImportCatalog("catalog.xml", builderTestCatalog()).
+		IncludeIDs(false, "AC-1", "ac-2.1").
+		IncludeFamily("Audit and Accountability", true).
+		ExcludeMatch("ac-2\\..*").
+		SetParam("ac-1_prm_1", "yearly").
+		AlterControl("ac-2", Add{Position: "ending", Props: []catalog.Prop{catalog.Prop{Class: "priority", Value: "P1"}}}).
+		Merge(Merge{AsIs: "true"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "baseline" || p.Title != "Baseline" || len(p.Imports) != 1 || p.Imports[0].Href.String() != "catalog.xml" {
+		t.Fatalf("unexpected profile %+v", p)
+	}
+	calls := p.Imports[0].Include.IdSelectors
+	expected := []Call{
+		Call{ControlId: "AC-1", WithSubcontrols: "no"},
+		Call{SubcontrolId: "ac-2.1", WithControl: "yes"},
+		Call{ControlId: "au-1", WithSubcontrols: "yes"},
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("expected call %v, got %v", expected[i], calls[i])
+		}
+	}
+	if p.Imports[0].Exclude.PatternSelectors[0].Pattern != "ac-2\\..*" {
+		t.Error("exclusion should apply to the import")
+	}
+	if len(p.Modify.ParamSettings) != 1 || len(p.Modify.Alterations) != 1 || p.Merge.AsIs == "" {
+		t.Errorf("modifications and merge should be set, got %+v and %+v", p.Modify, p.Merge)
+	}
+}
+
+func TestBuilderValidation(t *testing.T) {
+	tt := []struct {
+		name     string
+		build    func() *Builder
+		expected []string
+	}{
+		{
+			name:     "no import",
+			build:    func() *Builder { return NewBuilder("p", "").IncludeControls(false, "ac-1") },
+			expected: []string{"include of controls before any import", "no import"},
+		},
+		{
+			name: "unknown ids",
+			build: func() *Builder {
+				return NewBuilder("p", "").
+					ImportCatalog("catalog.xml", builderTestCatalog()).
+					IncludeControls(false, "ac-3").
+					IncludeSubcontrols("ac-1.1").
+					ExcludeControls("ac-2.1").
+					IncludeFamily("pe", false)
+			},
+			expected: []string{
+				"control ac-3 is not in the catalog of import 1",
+				"subcontrol ac-1.1 is not in the catalog of import 1",
+				"control ac-2.1 is not in the catalog of import 1",
+				"family pe is not in the catalog of import 1",
+			},
+		},
+		{
+			name: "patterns",
+			build: func() *Builder {
+				return NewBuilder("p", "").
+					ImportCatalog("catalog.xml", builderTestCatalog()).
+					IncludeMatch("(", false).
+					ExcludeMatch("pe-.*")
+			},
+			expected: []string{"invalid pattern (", "pattern pe-.* matches nothing in the catalog of import 1"},
+		},
+		{
+			name: "modifications",
+			build: func() *Builder {
+				return NewBuilder("p", "").
+					ImportCatalog("catalog.xml", builderTestCatalog()).
+					IncludeAll(true).
+					SetParam("ac-3_prm_1", "x").
+					AlterSubcontrol("ac-1.1").
+					Alter(Alter{})
+			},
+			expected: []string{
+				"alter without a control or subcontrol id",
+				"altered subcontrol ac-1.1 is not in any imported catalog",
+				"set parameter ac-3_prm_1 is not in any imported catalog",
+			},
+		},
+		{
+			name: "family without catalog",
+			build: func() *Builder {
+				return NewBuilder("p", "").Import("profile.xml").IncludeFamily("ac", false)
+			},
+			expected: []string{"family ac cannot be selected without the catalog of import 1"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.build().Build()
+			if err == nil {
+				t.Fatal("profile should be invalid")
+			}
+			for _, msg := range tc.expected {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("error should contain %q, got %v", msg, err)
+				}
+			}
+		})
+	}
+
+	// modifications are not checked when some import is not a known catalog
+	_, err := NewBuilder("p", "").
+		ImportCatalog("catalog.xml", builderTestCatalog()).
+		Import("other.xml").
+		SetParam("other_prm_1", "x").
+		Build()
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,394 @@
+// Code generated by go generate; DO NOT EDIT.
+package profile
+
+import (
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+// DeepCopy copies the Add, sharing no memory with it
+func (in *Add) DeepCopy() *Add {
+	if in == nil {
+		return nil
+	}
+	out := new(Add)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Add into out
+func (in *Add) DeepCopyInto(out *Add) {
+	*out = *in
+	if in.Props != nil {
+		out.Props = make([]catalog.Prop, len(in.Props))
+		for i := range in.Props {
+			in.Props[i].DeepCopyInto(&out.Props[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]catalog.Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	out.References = in.References.DeepCopy()
+	if in.Params != nil {
+		out.Params = make([]catalog.Param, len(in.Params))
+		for i := range in.Params {
+			in.Params[i].DeepCopyInto(&out.Params[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]catalog.Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+}
+
+// DeepCopy copies the All, sharing no memory with it
+func (in *All) DeepCopy() *All {
+	if in == nil {
+		return nil
+	}
+	out := new(All)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the All into out
+func (in *All) DeepCopyInto(out *All) {
+	*out = *in
+}
+
+// DeepCopy copies the Alter, sharing no memory with it
+func (in *Alter) DeepCopy() *Alter {
+	if in == nil {
+		return nil
+	}
+	out := new(Alter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Alter into out
+func (in *Alter) DeepCopyInto(out *Alter) {
+	*out = *in
+	if in.Removals != nil {
+		out.Removals = make([]Remove, len(in.Removals))
+		for i := range in.Removals {
+			in.Removals[i].DeepCopyInto(&out.Removals[i])
+		}
+	}
+	if in.Additions != nil {
+		out.Additions = make([]Add, len(in.Additions))
+		for i := range in.Additions {
+			in.Additions[i].DeepCopyInto(&out.Additions[i])
+		}
+	}
+}
+
+// DeepCopy copies the Call, sharing no memory with it
+func (in *Call) DeepCopy() *Call {
+	if in == nil {
+		return nil
+	}
+	out := new(Call)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Call into out
+func (in *Call) DeepCopyInto(out *Call) {
+	*out = *in
+}
+
+// DeepCopy copies the Combine, sharing no memory with it
+func (in *Combine) DeepCopy() *Combine {
+	if in == nil {
+		return nil
+	}
+	out := new(Combine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Combine into out
+func (in *Combine) DeepCopyInto(out *Combine) {
+	*out = *in
+}
+
+// DeepCopy copies the Custom, sharing no memory with it
+func (in *Custom) DeepCopy() *Custom {
+	if in == nil {
+		return nil
+	}
+	out := new(Custom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Custom into out
+func (in *Custom) DeepCopyInto(out *Custom) {
+	*out = *in
+	if in.IdSelectors != nil {
+		out.IdSelectors = make([]Call, len(in.IdSelectors))
+		for i := range in.IdSelectors {
+			in.IdSelectors[i].DeepCopyInto(&out.IdSelectors[i])
+		}
+	}
+	if in.PatternSelectors != nil {
+		out.PatternSelectors = make([]Match, len(in.PatternSelectors))
+		for i := range in.PatternSelectors {
+			in.PatternSelectors[i].DeepCopyInto(&out.PatternSelectors[i])
+		}
+	}
+	if in.Groups != nil {
+		out.Groups = make([]Group, len(in.Groups))
+		for i := range in.Groups {
+			in.Groups[i].DeepCopyInto(&out.Groups[i])
+		}
+	}
+}
+
+// DeepCopy copies the Exclude, sharing no memory with it
+func (in *Exclude) DeepCopy() *Exclude {
+	if in == nil {
+		return nil
+	}
+	out := new(Exclude)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Exclude into out
+func (in *Exclude) DeepCopyInto(out *Exclude) {
+	*out = *in
+	if in.IdSelectors != nil {
+		out.IdSelectors = make([]Call, len(in.IdSelectors))
+		for i := range in.IdSelectors {
+			in.IdSelectors[i].DeepCopyInto(&out.IdSelectors[i])
+		}
+	}
+	if in.PatternSelectors != nil {
+		out.PatternSelectors = make([]Match, len(in.PatternSelectors))
+		for i := range in.PatternSelectors {
+			in.PatternSelectors[i].DeepCopyInto(&out.PatternSelectors[i])
+		}
+	}
+}
+
+// DeepCopy copies the Group, sharing no memory with it
+func (in *Group) DeepCopy() *Group {
+	if in == nil {
+		return nil
+	}
+	out := new(Group)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Group into out
+func (in *Group) DeepCopyInto(out *Group) {
+	*out = *in
+	if in.Groups != nil {
+		out.Groups = make([]Group, len(in.Groups))
+		for i := range in.Groups {
+			in.Groups[i].DeepCopyInto(&out.Groups[i])
+		}
+	}
+	if in.IdSelectors != nil {
+		out.IdSelectors = make([]Call, len(in.IdSelectors))
+		for i := range in.IdSelectors {
+			in.IdSelectors[i].DeepCopyInto(&out.IdSelectors[i])
+		}
+	}
+	if in.PatternSelectors != nil {
+		out.PatternSelectors = make([]Match, len(in.PatternSelectors))
+		for i := range in.PatternSelectors {
+			in.PatternSelectors[i].DeepCopyInto(&out.PatternSelectors[i])
+		}
+	}
+}
+
+// DeepCopy copies the Import, sharing no memory with it
+func (in *Import) DeepCopy() *Import {
+	if in == nil {
+		return nil
+	}
+	out := new(Import)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Import into out
+func (in *Import) DeepCopyInto(out *Import) {
+	*out = *in
+	out.Href = in.Href.DeepCopy()
+	out.Include = in.Include.DeepCopy()
+	out.Exclude = in.Exclude.DeepCopy()
+}
+
+// DeepCopy copies the Include, sharing no memory with it
+func (in *Include) DeepCopy() *Include {
+	if in == nil {
+		return nil
+	}
+	out := new(Include)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Include into out
+func (in *Include) DeepCopyInto(out *Include) {
+	*out = *in
+	out.All = in.All.DeepCopy()
+	if in.IdSelectors != nil {
+		out.IdSelectors = make([]Call, len(in.IdSelectors))
+		for i := range in.IdSelectors {
+			in.IdSelectors[i].DeepCopyInto(&out.IdSelectors[i])
+		}
+	}
+	if in.PatternSelectors != nil {
+		out.PatternSelectors = make([]Match, len(in.PatternSelectors))
+		for i := range in.PatternSelectors {
+			in.PatternSelectors[i].DeepCopyInto(&out.PatternSelectors[i])
+		}
+	}
+}
+
+// DeepCopy copies the Match, sharing no memory with it
+func (in *Match) DeepCopy() *Match {
+	if in == nil {
+		return nil
+	}
+	out := new(Match)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Match into out
+func (in *Match) DeepCopyInto(out *Match) {
+	*out = *in
+}
+
+// DeepCopy copies the Merge, sharing no memory with it
+func (in *Merge) DeepCopy() *Merge {
+	if in == nil {
+		return nil
+	}
+	out := new(Merge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Merge into out
+func (in *Merge) DeepCopyInto(out *Merge) {
+	*out = *in
+	out.Combine = in.Combine.DeepCopy()
+	out.Custom = in.Custom.DeepCopy()
+}
+
+// DeepCopy copies the Modify, sharing no memory with it
+func (in *Modify) DeepCopy() *Modify {
+	if in == nil {
+		return nil
+	}
+	out := new(Modify)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Modify into out
+func (in *Modify) DeepCopyInto(out *Modify) {
+	*out = *in
+	if in.ParamSettings != nil {
+		out.ParamSettings = make([]SetParam, len(in.ParamSettings))
+		for i := range in.ParamSettings {
+			in.ParamSettings[i].DeepCopyInto(&out.ParamSettings[i])
+		}
+	}
+	if in.Alterations != nil {
+		out.Alterations = make([]Alter, len(in.Alterations))
+		for i := range in.Alterations {
+			in.Alterations[i].DeepCopyInto(&out.Alterations[i])
+		}
+	}
+}
+
+// DeepCopy copies the Profile, sharing no memory with it
+func (in *Profile) DeepCopy() *Profile {
+	if in == nil {
+		return nil
+	}
+	out := new(Profile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Profile into out
+func (in *Profile) DeepCopyInto(out *Profile) {
+	*out = *in
+	out.Merge = in.Merge.DeepCopy()
+	out.Modify = in.Modify.DeepCopy()
+	if in.Imports != nil {
+		out.Imports = make([]Import, len(in.Imports))
+		for i := range in.Imports {
+			in.Imports[i].DeepCopyInto(&out.Imports[i])
+		}
+	}
+}
+
+// DeepCopy copies the Remove, sharing no memory with it
+func (in *Remove) DeepCopy() *Remove {
+	if in == nil {
+		return nil
+	}
+	out := new(Remove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Remove into out
+func (in *Remove) DeepCopyInto(out *Remove) {
+	*out = *in
+}
+
+// DeepCopy copies the SetParam, sharing no memory with it
+func (in *SetParam) DeepCopy() *SetParam {
+	if in == nil {
+		return nil
+	}
+	out := new(SetParam)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the SetParam into out
+func (in *SetParam) DeepCopyInto(out *SetParam) {
+	*out = *in
+	if in.Descriptions != nil {
+		out.Descriptions = make([]catalog.Desc, len(in.Descriptions))
+		for i := range in.Descriptions {
+			in.Descriptions[i].DeepCopyInto(&out.Descriptions[i])
+		}
+	}
+	if in.Constraints != nil {
+		out.Constraints = make([]catalog.Constraint, len(in.Constraints))
+		for i := range in.Constraints {
+			in.Constraints[i].DeepCopyInto(&out.Constraints[i])
+		}
+	}
+	if in.Links != nil {
+		out.Links = make([]catalog.Link, len(in.Links))
+		for i := range in.Links {
+			in.Links[i].DeepCopyInto(&out.Links[i])
+		}
+	}
+	if in.Parts != nil {
+		out.Parts = make([]catalog.Part, len(in.Parts))
+		for i := range in.Parts {
+			in.Parts[i].DeepCopyInto(&out.Parts[i])
+		}
+	}
+	out.Select = in.Select.DeepCopy()
+}
@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+func TestDeepCopy(t *testing.T) {
+	href := catalog.Href{}
+	if err := href.UnmarshalJSON([]byte(`"catalog.xml"`)); err != nil {
+		t.Fatal(err)
+	}
+	p := &Profile{
+		ID:      "low",
+		Imports: []Import{{Href: &href, Include: &Include{IdSelectors: []Call{{ControlId: "ac-1"}}}}},
+		Merge:   &Merge{AsIs: "true"},
+		Modify: &Modify{
+			ParamSettings: []SetParam{{Id: "ac-1_prm_1", Constraints: []catalog.Constraint{{Value: "annually"}}}},
+			Alterations: []Alter{{
+				ControlId: "ac-1",
+				Additions: []Add{{Parts: []catalog.Part{catalog.NewPart("ac-1_smt.z", "", "Review <insert param-id=\"ac-1_prm_1\"/>")}}},
+			}},
+		},
+	}
+	copied := p.DeepCopy()
+	if !reflect.DeepEqual(copied, p) {
+		t.Fatal("a copy should equal the profile copied")
+	}
+
+	copied.Imports[0].Href.URL.Path = "other.xml"
+	copied.Imports[0].Include.IdSelectors[0].ControlId = "ac-2"
+	copied.Modify.ParamSettings[0].Constraints[0].Value = "monthly"
+	copied.Modify.Alterations[0].Additions[0].Parts[0].ModifyProse("ac-1_prm_1", "annually")
+	if p.Imports[0].Href.String() != "catalog.xml" || p.Imports[0].Include.IdSelectors[0].ControlId != "ac-1" {
+		t.Errorf("changing a copy should leave the imports of the profile unchanged, got %+v", p.Imports[0])
+	}
+	if p.Modify.ParamSettings[0].Constraints[0].Value != "annually" {
+		t.Error("changing a copy should leave the set-params of the profile unchanged")
+	}
+	if text := p.Modify.Alterations[0].Additions[0].Parts[0].Prose.Text(); text != "Review {ac-1_prm_1}" {
+		t.Errorf("changing a copy should leave the alters of the profile unchanged, got %q", text)
+	}
+}
@@ -0,0 +1,265 @@
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Overlay tailors a profile into another baseline, such as an agency-specific overlay of a
+// FedRAMP baseline. In YAML:
+//
+//	import: https://example.com/NIST_SP-800-53_rev4_catalog.xml
+//	add:
+//	  controls: [ac-20, ac-21]
+//	  subcontrols: [ac-2.5]
+//	remove:
+//	  controls: [ac-1]
+//	set-params:
+//	  - id: ac-1_prm_2
+//	    value: at least annually
+//	alters:
+//	  - controlId: ac-2
+//	    adds:
+//	      - position: ending
+//	        props:
+//	          - class: priority
+//	            value: P1
+//
+// Alters are written like in JSON profiles.
+type Overlay struct {
+	// Import is the href of the tailored import, the first import of the profile when empty.
+	// Removals then apply to all imports.
+	Import    string           `yaml:"import,omitempty"`
+	Add       OverlaySelection `yaml:"add,omitempty"`
+	Remove    OverlaySelection `yaml:"remove,omitempty"`
+	SetParams []OverlayParam   `yaml:"set-params,omitempty"`
+	Alters    []Alter          `yaml:"-"`
+}
+
+// OverlaySelection lists controls and subcontrols by id
+type OverlaySelection struct {
+	Controls    []string `yaml:"controls,omitempty"`
+	Subcontrols []string `yaml:"subcontrols,omitempty"`
+}
+
+// OverlayParam sets the value of a parameter, or constrains it
+type OverlayParam struct {
+	ID          string   `yaml:"id"`
+	Value       string   `yaml:"value,omitempty"`
+	Constraints []string `yaml:"constraints,omitempty"`
+}
+
+// ReadOverlay reads an overlay from YAML
+func ReadOverlay(r io.Reader) (*Overlay, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var o struct {
+		Overlay `yaml:",inline"`
+		Alters  []interface{} `yaml:"alters,omitempty"`
+	}
+	if err := yaml.UnmarshalStrict(b, &o); err != nil {
+		return nil, fmt.Errorf("invalid overlay: %v", err)
+	}
+	// alters are decoded through their JSON form, YAML maps having interface{} keys
+	for i, raw := range o.Alters {
+		b, err := json.Marshal(jsonValue(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid alter %d of overlay: %v", i+1, err)
+		}
+		var alt Alter
+		if err := json.Unmarshal(b, &alt); err != nil {
+			return nil, fmt.Errorf("invalid alter %d of overlay: %v", i+1, err)
+		}
+		o.Overlay.Alters = append(o.Overlay.Alters, alt)
+	}
+	return &o.Overlay, nil
+}
+
+// jsonValue converts the maps of a decoded YAML value to maps with string keys
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	}
+	return v
+}
+
+// Tailor applies an overlay to a profile. Added controls and subcontrols are called by the
+// tailored import unless it already selects them, in which case they are no longer excluded.
+// Removed ones are no longer called by the tailored import, or by any import when the overlay
+// names none, and are excluded: an import may still select them with all or a pattern, or
+// through the calls of their subcontrols, which bring in their control.
+// Parameter settings replace the value of the existing ones.
+func Tailor(p *Profile, o Overlay) error {
+	imp, err := tailoredImport(p, o.Import)
+	if err != nil {
+		return err
+	}
+	for _, id := range o.Add.Controls {
+		if err := addCall(imp, Call{ControlId: id}); err != nil {
+			return err
+		}
+	}
+	for _, id := range o.Add.Subcontrols {
+		if err := addCall(imp, Call{SubcontrolId: id, WithControl: "yes"}); err != nil {
+			return err
+		}
+	}
+	// FedRAMP baselines call subcontrols of the catalog next to their import of a NIST baseline
+	removing := []*Import{imp}
+	if o.Import == "" {
+		removing = removing[:0]
+		for i := range p.Imports {
+			removing = append(removing, &p.Imports[i])
+		}
+	}
+	for _, imp := range removing {
+		for _, id := range o.Remove.Controls {
+			removeCall(imp, Call{ControlId: id})
+		}
+		for _, id := range o.Remove.Subcontrols {
+			removeCall(imp, Call{SubcontrolId: id})
+		}
+	}
+
+	if len(o.SetParams) == 0 && len(o.Alters) == 0 {
+		return nil
+	}
+	if p.Modify == nil {
+		p.Modify = &Modify{}
+	}
+	for _, op := range o.SetParams {
+		setOverlayParam(p.Modify, op)
+	}
+	p.Modify.Alterations = append(p.Modify.Alterations, o.Alters...)
+	return nil
+}
+
+func tailoredImport(p *Profile, href string) (*Import, error) {
+	if len(p.Imports) == 0 {
+		return nil, fmt.Errorf("profile %s has no import to tailor", p.ID)
+	}
+	if href == "" {
+		return &p.Imports[0], nil
+	}
+	for i, imp := range p.Imports {
+		if imp.Href != nil && imp.Href.String() == href {
+			return &p.Imports[i], nil
+		}
+	}
+	return nil, fmt.Errorf("profile %s has no import of %s", p.ID, href)
+}
+
+// callID is the id of the control or subcontrol of a call, lower cased
+func callID(call Call) string {
+	if call.ControlId != "" {
+		return strings.ToLower(call.ControlId)
+	}
+	return strings.ToLower(call.SubcontrolId)
+}
+
+// sameTarget tells whether two calls select the same control or the same subcontrol
+func sameTarget(a, b Call) bool {
+	return (a.ControlId == "") == (b.ControlId == "") && callID(a) == callID(b)
+}
+
+func withoutCall(calls []Call, call Call) ([]Call, bool) {
+	kept := calls[:0]
+	removed := false
+	for _, c := range calls {
+		if sameTarget(c, call) {
+			removed = true
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept, removed
+}
+
+// selectsBroadly tells whether an import selects a control or subcontrol through all or a pattern
+func selectsBroadly(imp *Import, id string) (bool, error) {
+	if imp.Include == nil || imp.Include.All != nil {
+		return true, nil
+	}
+	for _, m := range imp.Include.PatternSelectors {
+		regex, err := regexp.Compile(fmt.Sprintf("(?i)^(?:%s)$", m.Pattern))
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %s: %v", m.Pattern, err)
+		}
+		if regex.MatchString(id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func addCall(imp *Import, call Call) error {
+	if imp.Exclude != nil {
+		imp.Exclude.IdSelectors, _ = withoutCall(imp.Exclude.IdSelectors, call)
+	}
+	broadly, err := selectsBroadly(imp, callID(call))
+	if err != nil || broadly {
+		return err
+	}
+	for _, c := range imp.Include.IdSelectors {
+		if sameTarget(c, call) {
+			return nil
+		}
+	}
+	imp.Include.IdSelectors = append(imp.Include.IdSelectors, call)
+	return nil
+}
+
+// removeCall excludes a control along with its subcontrols, or a subcontrol
+func removeCall(imp *Import, call Call) {
+	if imp.Include != nil {
+		imp.Include.IdSelectors, _ = withoutCall(imp.Include.IdSelectors, call)
+	}
+	if imp.Exclude == nil {
+		imp.Exclude = &Exclude{}
+	}
+	for _, c := range imp.Exclude.IdSelectors {
+		if sameTarget(c, call) {
+			return
+		}
+	}
+	imp.Exclude.IdSelectors = append(imp.Exclude.IdSelectors, call)
+}
+
+func setOverlayParam(m *Modify, op OverlayParam) {
+	var constraints []catalog.Constraint
+	for _, c := range op.Constraints {
+		constraints = append(constraints, catalog.Constraint{Value: c})
+	}
+	for i := range m.ParamSettings {
+		sp := &m.ParamSettings[i]
+		if sp.Id != op.ID {
+			continue
+		}
+		if op.Value != "" {
+			sp.Value = catalog.Value(op.Value)
+		}
+		if len(constraints) > 0 {
+			sp.Constraints = constraints
+		}
+		return
+	}
+	m.ParamSettings = append(m.ParamSettings, SetParam{Id: op.ID, Value: catalog.Value(op.Value), Constraints: constraints})
+}
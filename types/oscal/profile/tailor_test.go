@@ -0,0 +1,146 @@
+package profile
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/docker/oscalkit/types/oscal/catalog"
+)
+
+const testOverlay = `
+add:
+  controls: [ac-3]
+  subcontrols: [ac-2.1]
+remove:
+  controls: [AC-1]
+set-params:
+  - id: ac-1_prm_1
+    value: at least annually
+  - id: ac-2_prm_1
+    value: quarterly
+    constraints: [at least quarterly]
+alters:
+  - controlId: ac-2
+    adds:
+      - position: ending
+        props:
+          - class: priority
+            value: P1
+`
+
+func TestReadOverlay(t *testing.T) {
+	o, err := ReadOverlay(strings.NewReader(testOverlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.Add.Controls) != 1 || len(o.Add.Subcontrols) != 1 || len(o.Remove.Controls) != 1 {
+		t.Errorf("unexpected selections %+v and %+v", o.Add, o.Remove)
+	}
+	if len(o.SetParams) != 2 || o.SetParams[1].Constraints[0] != "at least quarterly" {
+		t.Errorf("unexpected parameters %+v", o.SetParams)
+	}
+	if len(o.Alters) != 1 || o.Alters[0].ControlId != "ac-2" || o.Alters[0].Additions[0].Props[0].Value != "P1" {
+		t.Errorf("unexpected alters %+v", o.Alters)
+	}
+
+	if _, err := ReadOverlay(strings.NewReader("adds:\n  controls: [ac-1]\n")); err == nil {
+		t.Error("unknown overlay fields should be rejected")
+	}
+}
+
+func TestTailor(t *testing.T) {
+	o, err := ReadOverlay(strings.NewReader(testOverlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewBuilder("baseline", "Baseline").
+		ImportCatalog("catalog.xml", builderTestCatalog()).
+		IncludeControls(false, "ac-1", "ac-2").
+		ExcludeSubcontrols("ac-2.1").
+		SetParam("ac-1_prm_1", "yearly").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Tailor(p, *o); err != nil {
+		t.Fatal(err)
+	}
+
+	imp := p.Imports[0]
+	expected := []Call{
+		Call{ControlId: "ac-2", WithSubcontrols: "no"},
+		Call{ControlId: "ac-3"},
+		Call{SubcontrolId: "ac-2.1", WithControl: "yes"},
+	}
+	if len(imp.Include.IdSelectors) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, imp.Include.IdSelectors)
+	}
+	for i := range expected {
+		if imp.Include.IdSelectors[i] != expected[i] {
+			t.Errorf("expected call %v, got %v", expected[i], imp.Include.IdSelectors[i])
+		}
+	}
+	// the removed control is excluded, the subcontrol calls of a baseline bringing it back otherwise
+	if len(imp.Exclude.IdSelectors) != 1 || imp.Exclude.IdSelectors[0] != (Call{ControlId: "AC-1"}) {
+		t.Errorf("added subcontrol should no longer be excluded and removed control should be, got %v", imp.Exclude.IdSelectors)
+	}
+	if p.Title != "Baseline" || p.Imports[0].Href.String() != "catalog.xml" {
+		t.Error("unrelated content should be kept")
+	}
+
+	params := p.Modify.ParamSettings
+	if len(params) != 2 || params[0].Value != "at least annually" || params[1].Value != "quarterly" {
+		t.Errorf("unexpected parameters %+v", params)
+	}
+	if len(params[1].Constraints) != 1 || params[1].Constraints[0] != (catalog.Constraint{Value: "at least quarterly"}) {
+		t.Errorf("unexpected constraints %+v", params[1].Constraints)
+	}
+	if len(p.Modify.Alterations) != 1 {
+		t.Errorf("alter should be added, got %+v", p.Modify.Alterations)
+	}
+}
+
+func TestTailorBroadSelection(t *testing.T) {
+	p, err := NewBuilder("baseline", "").
+		ImportCatalog("catalog.xml", builderTestCatalog()).
+		IncludeMatch("ac-.*", true).
+		ExcludeControls("ac-2").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := Overlay{
+		Add:    OverlaySelection{Controls: []string{"ac-2"}},
+		Remove: OverlaySelection{Controls: []string{"ac-1"}},
+	}
+	if err := Tailor(p, o); err != nil {
+		t.Fatal(err)
+	}
+	imp := p.Imports[0]
+	if len(imp.Include.IdSelectors) != 0 {
+		t.Errorf("controls selected by a pattern should not be called, got %v", imp.Include.IdSelectors)
+	}
+	if len(imp.Exclude.IdSelectors) != 1 || imp.Exclude.IdSelectors[0] != (Call{ControlId: "ac-1"}) {
+		t.Errorf("removed control should be excluded instead of ac-2, got %v", imp.Exclude.IdSelectors)
+	}
+
+	if err := Tailor(p, Overlay{Import: "other.xml"}); err == nil || !strings.Contains(err.Error(), "no import of other.xml") {
+		t.Errorf("unknown import should fail, got %v", err)
+	}
+}
+
+func TestAsIsJSONRoundTrip(t *testing.T) {
+	m := Merge{AsIs: "true"}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read Merge
+	if err := json.Unmarshal(b, &read); err != nil {
+		t.Fatal(err)
+	}
+	if read.AsIs != "true" {
+		t.Errorf("expected as-is true, got %s", read.AsIs)
+	}
+}
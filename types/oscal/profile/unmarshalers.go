@@ -1,6 +1,54 @@
 package profile
 
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+//go:generate go run ../gen_deepcopy.go -o deepcopy.go -dep catalog=../catalog profile.go
+
+// UnmarshalJSON reads as-is from a JSON string, or from any other JSON value as written. A
+// false as-is is left empty, as if there were none.
 func (a *AsIs) UnmarshalJSON(b []byte) error {
-	*a = AsIs(string(b))
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		value = string(b)
+	}
+	*a = asIs(value)
+	return nil
+}
+
+// UnmarshalYAML reads as-is from a YAML string, or from any other YAML scalar as written. A
+// false as-is is left empty, as if there were none.
+func (a *AsIs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	*a = asIs(fmt.Sprint(value))
+	return nil
+}
+
+func asIs(value string) AsIs {
+	if strings.EqualFold(strings.TrimSpace(value), "false") {
+		return ""
+	}
+	return AsIs(value)
+}
+
+// UnmarshalXML keeps track of an as-is element being present. The element has no content,
+// so an empty element is recorded as "true". An element holding false is left empty, as in JSON
+// and YAML.
+func (a *AsIs) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var value string
+	if err := d.DecodeElement(&value, &start); err != nil {
+		return err
+	}
+	if value == "" {
+		value = "true"
+	}
+	*a = asIs(value)
 	return nil
 }
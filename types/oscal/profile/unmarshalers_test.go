@@ -0,0 +1,48 @@
+package profile
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAsIs(t *testing.T) {
+	tt := []struct {
+		format   string
+		input    string
+		expected AsIs
+	}{
+		{"json", `{"asIs": true}`, "true"},
+		{"json", `{"asIs": "true"}`, "true"},
+		{"json", `{"asIs": false}`, ""},
+		{"json", `{"asIs": "false"}`, ""},
+		{"json", `{}`, ""},
+		{"yaml", "asis: true\n", "true"},
+		{"yaml", "asis: false\n", ""},
+		{"xml", `<merge><as-is/></merge>`, "true"},
+		{"xml", `<merge><as-is>true</as-is></merge>`, "true"},
+		{"xml", `<merge><as-is>false</as-is></merge>`, ""},
+		{"xml", `<merge/>`, ""},
+	}
+	for _, tc := range tt {
+		var merge Merge
+		var err error
+		switch tc.format {
+		case "json":
+			err = json.Unmarshal([]byte(tc.input), &merge)
+		case "yaml":
+			err = yaml.Unmarshal([]byte(tc.input), &merge)
+		case "xml":
+			err = xml.Unmarshal([]byte(tc.input), &merge)
+		}
+		if err != nil {
+			t.Errorf("cannot read %s: %v", tc.input, err)
+			continue
+		}
+		if merge.AsIs != tc.expected {
+			t.Errorf("expected as-is %q from %s, got %q", tc.expected, tc.input, merge.AsIs)
+		}
+	}
+}
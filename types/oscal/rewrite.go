@@ -0,0 +1,406 @@
+package oscal
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Rewrite writes the OSCAL object over the document it was read from, in the format and JSON
+// encoding of that document. The children of the catalog or profile that the oscalkit types
+// model are written from the object, in place of those of the document. Anything else of the
+// document is kept as it is: comments, the attributes of the root element, and the elements and
+// members the types do not model, such as the publication information of published baselines.
+func (o *OSCAL) Rewrite(w io.Writer, original []byte) error {
+	root, t := o.root()
+	if root == nil {
+		return errors.New("nothing to rewrite")
+	}
+	var (
+		b   []byte
+		err error
+	)
+	trimmed := bytes.TrimSpace(original)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '<':
+		b, err = o.rewriteXML(original, t)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		b, err = o.rewriteJSON(original, t)
+	default:
+		b, err = o.rewriteYAML(original, t)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// root gives the catalog or profile of the object along with its type
+func (o *OSCAL) root() (interface{}, reflect.Type) {
+	switch {
+	case o.Catalog != nil:
+		return o.Catalog, reflect.TypeOf(o.Catalog).Elem()
+	case o.Profile != nil:
+		return o.Profile, reflect.TypeOf(o.Profile).Elem()
+	}
+	return nil, nil
+}
+
+// childRef refers to a child of either the original or the encoded document
+type childRef struct {
+	encoded bool
+	i       int
+}
+
+// mergeChildren orders the children of a rewritten document. Children the types do not model
+// stay where the original has them. The encoded children of a name take the place of the first
+// original child of that name. Those the original lacks go before the next encoded child, at the
+// end when there is none.
+func mergeChildren(original, encoded []string, modeled func(name string) bool) []childRef {
+	byName := make(map[string][]int)
+	for i, name := range encoded {
+		byName[name] = append(byName[name], i)
+	}
+	var refs []childRef
+	placed := make(map[string]bool)
+	for i, name := range original {
+		if !modeled(name) {
+			refs = append(refs, childRef{i: i})
+			continue
+		}
+		if placed[name] {
+			continue
+		}
+		placed[name] = true
+		for _, j := range byName[name] {
+			refs = append(refs, childRef{encoded: true, i: j})
+		}
+	}
+	for j, name := range encoded {
+		if placed[name] {
+			continue
+		}
+		placed[name] = true
+		at := len(refs)
+		for k, ref := range refs {
+			if ref.encoded && ref.i > j {
+				at = k
+				break
+			}
+		}
+		group := make([]childRef, 0, len(byName[name]))
+		for _, i := range byName[name] {
+			group = append(group, childRef{encoded: true, i: i})
+		}
+		refs = append(refs[:at], append(group, refs[at:]...)...)
+	}
+	return refs
+}
+
+// xmlChild is a child element of the root of an XML document
+type xmlChild struct {
+	name string
+	// prefix is what comes between the previous child and this one: whitespace and comments
+	prefix []byte
+	raw    []byte
+}
+
+// xmlDocument is an XML document split around the children of its root element
+type xmlDocument struct {
+	// head runs to the end of the start tag of the root
+	head     []byte
+	prefixed bool
+	children []xmlChild
+	// tail runs from the end of the last child
+	tail []byte
+}
+
+func splitXML(doc []byte) (*xmlDocument, error) {
+	x := &xmlDocument{}
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	depth := 0
+	var last, start int64
+	var name string
+	for {
+		offset := d.InputOffset()
+		token, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch depth {
+			case 1:
+				last = d.InputOffset()
+				x.head = doc[:last]
+				x.prefixed = token.Name.Space != ""
+			case 2:
+				start, name = offset, token.Name.Local
+			}
+		case xml.EndElement:
+			switch depth {
+			case 1:
+				x.tail = doc[last:]
+			case 2:
+				end := d.InputOffset()
+				x.children = append(x.children, xmlChild{name: name, prefix: doc[last:start], raw: doc[start:end]})
+				last = end
+			}
+			depth--
+		}
+	}
+	if x.head == nil || x.tail == nil {
+		return nil, errMalformed
+	}
+	return x, nil
+}
+
+// xmlIndent gives the indentation of the first child of a document, two spaces when it has none
+func (x *xmlDocument) xmlIndent() string {
+	if len(x.children) > 0 {
+		prefix := string(x.children[0].prefix)
+		indent := prefix[strings.LastIndex(prefix, "\n")+1:]
+		if strings.Contains(prefix, "\n") && strings.TrimSpace(indent) == "" && indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
+// xmlElements names the child elements a type models
+func xmlElements(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("xml")
+		if sf.Name == "XMLName" || tag == "-" || strings.Contains(tag, ",attr") {
+			continue
+		}
+		names[xmlName(tag, sf.Name)] = true
+	}
+	return names
+}
+
+func (o *OSCAL) rewriteXML(original []byte, t reflect.Type) ([]byte, error) {
+	doc, err := splitXML(original)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if doc.prefixed {
+		// children written without the prefix of the root would leave its namespace
+		err := o.XML(&buf, true)
+		return buf.Bytes(), err
+	}
+	e := xml.NewEncoder(&buf)
+	e.Indent("", doc.xmlIndent())
+	if err := e.Encode(o); err != nil {
+		return nil, err
+	}
+	encoded, err := splitXML(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	names := func(children []xmlChild) []string {
+		var names []string
+		for _, c := range children {
+			names = append(names, c.name)
+		}
+		return names
+	}
+	modeled := xmlElements(t)
+	firstOriginal := make(map[string]int)
+	for i := len(doc.children) - 1; i >= 0; i-- {
+		firstOriginal[doc.children[i].name] = i
+	}
+	var out bytes.Buffer
+	out.Write(doc.head)
+	lastEncoded := ""
+	for _, ref := range mergeChildren(names(doc.children), names(encoded.children), func(name string) bool { return modeled[name] }) {
+		if !ref.encoded {
+			c := doc.children[ref.i]
+			out.Write(c.prefix)
+			out.Write(c.raw)
+			lastEncoded = ""
+			continue
+		}
+		c := encoded.children[ref.i]
+		prefix := c.prefix
+		// the first child written in place of original ones keeps the comments before them
+		if i, ok := firstOriginal[c.name]; ok && c.name != lastEncoded {
+			prefix = doc.children[i].prefix
+		}
+		out.Write(prefix)
+		out.Write(c.raw)
+		lastEncoded = c.name
+	}
+	out.Write(doc.tail)
+	return out.Bytes(), nil
+}
+
+// rootMember finds the catalog or profile member of a document
+func rootMember(keys []string) (int, bool) {
+	for i, key := range keys {
+		if key == "catalog" || key == "profile" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (o *OSCAL) rewriteJSON(original []byte, t reflect.Type) ([]byte, error) {
+	read := func(b []byte) (object, int, error) {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		tree, err := readValue(d)
+		if err != nil {
+			return nil, 0, err
+		}
+		doc, ok := tree.(object)
+		if !ok {
+			return nil, 0, errMalformed
+		}
+		var keys []string
+		for _, m := range doc {
+			keys = append(keys, m.key)
+		}
+		i, ok := rootMember(keys)
+		if !ok {
+			return nil, 0, errMalformed
+		}
+		if _, ok := doc[i].value.(object); !ok {
+			return nil, 0, errMalformed
+		}
+		return doc, i, nil
+	}
+	doc, i, err := read(original)
+	if err != nil {
+		return nil, err
+	}
+	root := doc[i].value.(object)
+	encoding := LegacyJSON
+	if isOfficial(root, t) {
+		encoding = OfficialJSON
+	}
+	var buf bytes.Buffer
+	if err := o.EncodeJSON(&buf, false, encoding); err != nil {
+		return nil, err
+	}
+	encodedDoc, j, err := read(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	encoded := encodedDoc[j].value.(object)
+
+	keys := func(o object) []string {
+		var keys []string
+		for _, m := range o {
+			keys = append(keys, m.key)
+		}
+		return keys
+	}
+	fields := fieldsOf(t)
+	modeled := func(key string) bool {
+		_, legacy := fields.byLegacy[key]
+		_, official := fields.byOfficial[key]
+		return legacy || official
+	}
+	merged := object{}
+	for _, ref := range mergeChildren(keys(root), keys(encoded), modeled) {
+		if ref.encoded {
+			merged = append(merged, encoded[ref.i])
+		} else {
+			merged = append(merged, root[ref.i])
+		}
+	}
+	doc[i].value = merged
+
+	buf.Reset()
+	e := json.NewEncoder(&buf)
+	e.SetIndent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlKeys names the keys a type is written with in YAML
+func yamlKeys(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		names[name] = true
+	}
+	return names
+}
+
+func (o *OSCAL) rewriteYAML(original []byte, t reflect.Type) ([]byte, error) {
+	read := func(b []byte) (yaml.MapSlice, int, error) {
+		var doc yaml.MapSlice
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, 0, err
+		}
+		var keys []string
+		for _, item := range doc {
+			keys = append(keys, fmt.Sprint(item.Key))
+		}
+		i, ok := rootMember(keys)
+		if !ok {
+			return nil, 0, errMalformed
+		}
+		if _, ok := doc[i].Value.(yaml.MapSlice); !ok {
+			return nil, 0, errMalformed
+		}
+		return doc, i, nil
+	}
+	doc, i, err := read(original)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := o.YAML(&buf); err != nil {
+		return nil, err
+	}
+	encodedDoc, j, err := read(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	root := doc[i].Value.(yaml.MapSlice)
+	encoded := encodedDoc[j].Value.(yaml.MapSlice)
+
+	keys := func(m yaml.MapSlice) []string {
+		var keys []string
+		for _, item := range m {
+			keys = append(keys, fmt.Sprint(item.Key))
+		}
+		return keys
+	}
+	modeled := yamlKeys(t)
+	merged := yaml.MapSlice{}
+	for _, ref := range mergeChildren(keys(root), keys(encoded), func(key string) bool { return modeled[key] }) {
+		if ref.encoded {
+			merged = append(merged, encoded[ref.i])
+		} else {
+			merged = append(merged, root[ref.i])
+		}
+	}
+	doc[i].Value = merged
+	return yaml.Marshal(doc)
+}
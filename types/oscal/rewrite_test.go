@@ -0,0 +1,106 @@
+package oscal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/docker/oscalkit/types/oscal/profile"
+)
+
+const fedrampHigh = "../../test_util/artifacts/FedRAMP_HIGH-baseline_profile.xml"
+
+func rewrite(t *testing.T, o *OSCAL, original []byte) []byte {
+	var buf bytes.Buffer
+	if err := o.Rewrite(&buf, original); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRewriteTailoredBaseline(t *testing.T) {
+	original, err := ioutil.ReadFile(fedrampHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := New(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay := profile.Overlay{
+		Add:       profile.OverlaySelection{Controls: []string{"ac-16"}},
+		Remove:    profile.OverlaySelection{Controls: []string{"ac-1"}},
+		SetParams: []profile.OverlayParam{{ID: "ac-2_prm_1", Value: "quarterly"}},
+	}
+	if err := profile.Tailor(o.Profile, overlay); err != nil {
+		t.Fatal(err)
+	}
+	tailored := rewrite(t, o, original)
+
+	publication := regexp.MustCompile(`(?s)<publication_information>.*</publication_information>`).Find(original)
+	for _, kept := range [][]byte{[]byte("<!-- Created: 8/6/2018 7:55:40 PM -->"), publication} {
+		if !bytes.Contains(tailored, kept) {
+			t.Errorf("tailored baseline should keep %s", kept)
+		}
+	}
+	if !bytes.HasPrefix(tailored[bytes.Index(tailored, publication)+len(publication):], []byte("\n\t<import ")) {
+		t.Error("imports should follow the publication information, indented with tabs")
+	}
+
+	read, err := New(bytes.NewReader(tailored))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, actual bytes.Buffer
+	if err := o.XML(&expected, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := read.XML(&actual, true); err != nil {
+		t.Fatal(err)
+	}
+	if expected.String() != actual.String() {
+		t.Error("tailored baseline should read back as tailored")
+	}
+}
+
+func TestRewriteKeepsUnmodeledMembers(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		expected []string
+	}{
+		{
+			name:     "legacy json",
+			original: `{"profile": {"id": "p", "metadata": {"author": "me"}, "imports": [{"href": "catalog.xml", "include": {"calls": [{"controlId": "ac-1"}]}}]}}`,
+			expected: []string{`"id": "p"`, `"metadata": {`, `"author": "me"`, `"controlId": "ac-2"`},
+		},
+		{
+			name:     "official json",
+			original: `{"profile": {"id": "p", "metadata": {"author": "me"}, "imports": [{"href": "catalog.xml", "include": {"calls": [{"control-id": "ac-1"}]}}]}}`,
+			expected: []string{`"metadata": {`, `"control-id": "ac-2"`},
+		},
+		{
+			name:     "yaml",
+			original: "profile:\n  id: p\n  metadata:\n    author: me\n  imports:\n  - href: catalog.xml\n    include:\n      idselectors:\n      - controlid: ac-1\n",
+			expected: []string{"metadata:\n    author: me\n", "controlid: ac-2"},
+		},
+	}
+	for _, test := range tests {
+		o, err := New(strings.NewReader(test.original))
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Profile.Imports[0].Include.IdSelectors[0].ControlId = "ac-2"
+		rewritten := string(rewrite(t, o, []byte(test.original)))
+		for _, expected := range test.expected {
+			if !strings.Contains(rewritten, expected) {
+				t.Errorf("%s: expected %q in\n%s", test.name, expected, rewritten)
+			}
+		}
+		if strings.Index(rewritten, "metadata") > strings.Index(rewritten, "imports") {
+			t.Errorf("%s: unmodeled members should stay in place:\n%s", test.name, rewritten)
+		}
+	}
+}
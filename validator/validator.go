@@ -2,8 +2,10 @@ package validator
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"github.com/santhosh-tekuri/jsonschema"
 	"github.com/santhosh-tekuri/jsonschema/loader"
 	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // Workaround for unpublished schemas referenced by http://csrc.nist.gov/ns/oscal
@@ -49,7 +52,7 @@ func (oscalLoader) Load(url string) (io.ReadCloser, error) {
 }
 
 // Validate validates one or more JSON files against a specific
-// JSON schema.
+// JSON schema. YAML files are validated as the equivalent JSON.
 func (j jsonValidator) Validate(file ...string) error {
 	basePath = filepath.Dir(j.SchemaFile)
 	schema, err := jsonschema.Compile(j.SchemaFile)
@@ -66,7 +69,15 @@ func (j jsonValidator) Validate(file ...string) error {
 		}
 		defer rawFile.Close()
 
-		if err = schema.Validate(rawFile); err != nil {
+		var doc io.Reader = rawFile
+		if ext := filepath.Ext(f); ext == ".yaml" || ext == ".yml" {
+			doc, err = yamlToJSON(rawFile)
+			if err != nil {
+				return fmt.Errorf("Error reading YAML file: %s, %v", f, err)
+			}
+		}
+
+		if err = schema.Validate(doc); err != nil {
 			return err
 		}
 
@@ -76,6 +87,40 @@ func (j jsonValidator) Validate(file ...string) error {
 	return nil
 }
 
+// yamlToJSON converts a YAML document to JSON
+func yamlToJSON(r io.Reader) (io.Reader, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(jsonValue(doc))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// jsonValue converts the maps of a decoded YAML value to maps with string keys
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	}
+	return v
+}
+
 // Validate validates one or more XML files against a specific
 // XML schema (.xsd). Wrapper around `xmllint`
 func (x xmlValidator) Validate(file ...string) error {
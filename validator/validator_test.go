@@ -2,6 +2,9 @@ package validator
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -151,3 +154,37 @@ func TestXMLValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONValidateYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"schema.json": `{
+			"type": "object",
+			"required": ["profile"],
+			"properties": {"profile": {"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}}}}
+		}`,
+		"valid.yaml":   "profile:\n  id: low\n",
+		"invalid.yml":  "profile:\n  title: no id\n",
+		"notyaml.yaml": "profile: [unclosed\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	j := jsonValidator{SchemaFile: filepath.Join(dir, "schema.json")}
+	if err := j.Validate(filepath.Join(dir, "valid.yaml")); err != nil {
+		t.Errorf("expected valid YAML to validate against the JSON schema, got %v", err)
+	}
+	for _, name := range []string{"invalid.yml", "notyaml.yaml"} {
+		if err := j.Validate(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected %s to fail validation", name)
+		}
+	}
+}